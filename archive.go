@@ -0,0 +1,84 @@
+package phargo
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"math"
+)
+
+// ZipExtraMetadata is zip extra field ID ("PH") with entry serialized metadata, written by [Phar.WriteZip]
+const ZipExtraMetadata = 0x4850
+
+// zipMaxMetadata is biggest entry metadata in zip extra field, it share 64KiB extra data with
+// its 4 bytes header, extended timestamp (9 bytes) and zip64 (up to 28 bytes) fields added by [zip.Writer]
+const zipMaxMetadata = math.MaxUint16 - 4 - 9 - 28
+
+// WriteZip add all entries to zw, deflating files and storing folders.
+// Entry metadata up to 64KiB, less other extra fields, is written to [ZipExtraMetadata] extra field, bigger metadata is skipped.
+// zw is not closed. Errors are [*fs.PathError] with entry name as path.
+func (phar *Phar) WriteZip(zw *zip.Writer) error {
+	for _, file := range phar.Files {
+		header, err := zip.FileInfoHeader(file.FileInfo())
+		if err != nil {
+			return &fs.PathError{Op: "zip", Path: file.Filename, Err: err}
+		}
+		header.Name, header.Method = file.Filename, zip.Deflate
+		if file.FileInfo().IsDir() {
+			header.Name += "/"
+			header.Method = zip.Store
+		}
+		if meta := file.MetaSerialized; len(meta) > 0 && len(meta) <= zipMaxMetadata {
+			header.Extra = binary.LittleEndian.AppendUint16(header.Extra, ZipExtraMetadata)
+			header.Extra = binary.LittleEndian.AppendUint16(header.Extra, uint16(len(meta)))
+			header.Extra = append(header.Extra, meta...)
+		}
+
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			return &fs.PathError{Op: "zip", Path: file.Filename, Err: err}
+		} else if file.FileInfo().IsDir() {
+			continue
+		} else if err := file.copyTo(w); err != nil {
+			return &fs.PathError{Op: "zip", Path: file.Filename, Err: err}
+		}
+	}
+	return nil
+}
+
+// WriteTar add all entries to tw with names, sizes, modes and modification times.
+// tw is not closed. Errors are [*fs.PathError] with entry name as path.
+func (phar *Phar) WriteTar(tw *tar.Writer) error {
+	for _, file := range phar.Files {
+		header, err := tar.FileInfoHeader(file.FileInfo(), "")
+		if err != nil {
+			return &fs.PathError{Op: "tar", Path: file.Filename, Err: err}
+		}
+		header.Name = file.Filename
+		if header.Typeflag == tar.TypeDir {
+			header.Name += "/"
+		}
+
+		if err := tw.WriteHeader(header); err != nil {
+			return &fs.PathError{Op: "tar", Path: file.Filename, Err: err}
+		} else if header.Typeflag == tar.TypeDir {
+			continue
+		} else if err := file.copyTo(tw); err != nil {
+			return &fs.PathError{Op: "tar", Path: file.Filename, Err: err}
+		}
+	}
+	return nil
+}
+
+// copyTo write decompressed entry content to w
+func (file *File) copyTo(w io.Writer) error {
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = copyBuffer(w, r)
+	return err
+}
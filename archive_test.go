@@ -0,0 +1,189 @@
+package phargo
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestWriteZip(t *testing.T) {
+	osFile, err := os.Open("./testdata/metadata_dir_sha256.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+
+	pharInfo, err := NewReaderFromFile(osFile)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	var buff bytes.Buffer
+	zw := zip.NewWriter(&buff)
+	if err := pharInfo.WriteZip(zw); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if err := zw.Close(); err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(zr.File) != len(pharInfo.Files) {
+		t.Errorf("Expect %d zip entries, got %d", len(pharInfo.Files), len(zr.File))
+		return
+	}
+
+	for index, file := range pharInfo.Files {
+		zipFile := zr.File[index]
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		r, err := zipFile.Open()
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		} else if int64(len(data)) != file.SizeUncompressed || zipFile.CRC32 != file.CRC {
+			t.Errorf("%s content differ in zip", file.Filename)
+			return
+		}
+
+		if len(file.MetaSerialized) > 0 {
+			extra := zipFile.Extra
+			if len(extra) < 4 || binary.LittleEndian.Uint16(extra) != ZipExtraMetadata || !bytes.Equal(extra[4:4+binary.LittleEndian.Uint16(extra[2:])], file.MetaSerialized) {
+				t.Errorf("%s metadata not in zip extra field", file.Filename)
+				return
+			}
+		}
+	}
+}
+
+func TestWriteZipEmptyFile(t *testing.T) {
+	data := buildPhar(testEntry{name: "dir/", flags: EntryPermDefDir}, testEntry{name: "dir/empty.txt"})
+	pharInfo, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	var buff bytes.Buffer
+	zw := zip.NewWriter(&buff)
+	if err := pharInfo.WriteZip(zw); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if err := zw.Close(); err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(zr.File) != 2 || zr.File[0].Name != "dir/" || !zr.File[0].Mode().IsDir() {
+		t.Errorf("Wrong zip folder entry: %v", zr.File)
+		return
+	} else if file := zr.File[1]; file.Name != "dir/empty.txt" || !file.Mode().IsRegular() || file.UncompressedSize64 != 0 {
+		t.Errorf("Wrong zip empty file entry: %s %s", file.Name, file.Mode())
+		return
+	}
+}
+
+func TestWriteZipBigMetadata(t *testing.T) {
+	data := buildPhar(testEntry{name: "fit.txt", data: []byte("FIT")}, testEntry{name: "big.txt", data: []byte("BIG")})
+	pharInfo, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	// Metadata filling extra field with timestamp field added by zip writer
+	pharInfo.Files[0].MetaSerialized = bytes.Repeat([]byte("m"), zipMaxMetadata)
+	pharInfo.Files[1].MetaSerialized = bytes.Repeat([]byte("m"), math.MaxUint16-4)
+
+	var buff bytes.Buffer
+	zw := zip.NewWriter(&buff)
+	if err := pharInfo.WriteZip(zw); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if err := zw.Close(); err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(zr.File) != 2 {
+		t.Errorf("Expect 2 zip entries, got %d", len(zr.File))
+		return
+	} else if extra := zr.File[0].Extra; len(extra) < 4 || binary.LittleEndian.Uint16(extra) != ZipExtraMetadata || int(binary.LittleEndian.Uint16(extra[2:])) != zipMaxMetadata {
+		t.Error("fit.txt metadata not in zip extra field")
+		return
+	} else if bytes.Contains(zr.File[1].Extra, []byte("mmmm")) {
+		t.Error("big.txt metadata should be skipped")
+		return
+	}
+}
+
+func TestWriteTar(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "dir/", flags: EntryPermDefDir},
+		testEntry{name: "dir/a.txt", data: []byte("AAAA"), flags: 0640},
+		testEntry{name: "dir/empty.txt", flags: 0644},
+	)
+	pharInfo, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	var buff bytes.Buffer
+	tw := tar.NewWriter(&buff)
+	if err := pharInfo.WriteTar(tw); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if err := tw.Close(); err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	tr := tar.NewReader(&buff)
+	for _, expected := range []struct {
+		name    string
+		mode    int64
+		content string
+	}{{"dir/", 0777, ""}, {"dir/a.txt", 0640, "AAAA"}, {"dir/empty.txt", 0644, ""}} {
+		header, err := tr.Next()
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		} else if isDir := expected.name[len(expected.name)-1] == '/'; (header.Typeflag == tar.TypeDir) != isDir || (!isDir && header.Typeflag != tar.TypeReg) {
+			t.Errorf("Wrong type to %s: %q", expected.name, header.Typeflag)
+			return
+		} else if header.Name != expected.name || header.Mode&0777 != expected.mode || !header.ModTime.Equal(pharInfo.Files[0].Timestamp) {
+			t.Errorf("Wrong header to %s: %+v", expected.name, header)
+			return
+		} else if content, _ := io.ReadAll(tr); string(content) != expected.content {
+			t.Errorf("Wrong content to %s: %q", expected.name, content)
+			return
+		}
+	}
+}
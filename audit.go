@@ -0,0 +1,106 @@
+package phargo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Audit thresholds, entries above them are reported as risky
+const (
+	auditMaxCompressionRatio = 100     // Uncompressed size by compressed size
+	auditMaxMetadataLen      = 1 << 20 // Archive or entry serialized metadata
+)
+
+const (
+	AuditTraversalName    AuditKind = iota + 1 // Entry name escape extraction folder, is absolute or has backslash
+	AuditCompressionRatio                      // Compressed entry expand too much, like zip bombs
+	AuditHugeMetadata                          // Archive or entry metadata too big
+	AuditExecutable                            // Entry with executable permission bits
+	AuditWeakSignature                         // Archive unsigned or signed with MD5 or SHA1
+	AuditDuplicateName                         // Entry name used by more than one entry
+)
+
+var auditKindName = map[AuditKind]string{
+	AuditTraversalName:    "traversal_name",
+	AuditCompressionRatio: "compression_ratio",
+	AuditHugeMetadata:     "huge_metadata",
+	AuditExecutable:       "executable",
+	AuditWeakSignature:    "weak_signature",
+	AuditDuplicateName:    "duplicate_name",
+}
+
+// AuditKind identify risky trait found by [Audit]
+type AuditKind int
+
+func (kind AuditKind) String() string {
+	if str, ok := auditKindName[kind]; ok {
+		return str
+	}
+	return "unknown"
+}
+
+func (kind AuditKind) MarshalText() (text []byte, err error) {
+	return []byte(kind.String()), nil
+}
+
+// AuditFinding is risky trait of archive or entry
+type AuditFinding struct {
+	Kind   AuditKind
+	Entry  string // Entry name, empty to archive findings
+	Detail string
+}
+
+func (finding AuditFinding) String() string {
+	if finding.Entry == "" {
+		return fmt.Sprintf("%s: %s", finding.Kind, finding.Detail)
+	}
+	return fmt.Sprintf("%s: %q %s", finding.Kind, finding.Entry, finding.Detail)
+}
+
+// AuditReport is risky traits found in archive
+type AuditReport struct {
+	Findings []AuditFinding
+}
+
+// OK report if no risky trait was found
+func (report AuditReport) OK() bool { return len(report.Findings) == 0 }
+
+// Audit summarize risky traits of untrusted archive, without reading entries content
+func Audit(p *Phar) AuditReport {
+	var report AuditReport
+	add := func(kind AuditKind, entry, format string, args ...any) {
+		report.Findings = append(report.Findings, AuditFinding{Kind: kind, Entry: entry, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case p.Signature == nil:
+		add(AuditWeakSignature, "", "archive is unsigned")
+	case p.Signature.Signature == SignatureMD5, p.Signature.Signature == SignatureSHA1:
+		add(AuditWeakSignature, "", "archive signed with weak %s", p.Signature.Signature)
+	}
+	if p.Manifest != nil && len(p.Manifest.Metadata) > auditMaxMetadataLen {
+		add(AuditHugeMetadata, "", "archive has %d bytes of metadata", len(p.Manifest.Metadata))
+	}
+
+	seen := make(map[string]bool, len(p.Files))
+	for _, file := range p.Files {
+		if isAbsoluteName(file.Filename) || strings.Contains(file.Filename, `\`) || checkEntryPath(file.Filename) != nil {
+			add(AuditTraversalName, file.Filename, "is not local to extraction folder")
+		}
+		if seen[file.Filename] {
+			add(AuditDuplicateName, file.Filename, "is duplicated")
+		}
+		seen[file.Filename] = true
+
+		if file.Flags.Compression() != EntryCompressedNone && file.SizeUncompressed > auditMaxCompressionRatio*max(file.SizeCompressed, 1) {
+			add(AuditCompressionRatio, file.Filename, "expand %d bytes to %d", file.SizeCompressed, file.SizeUncompressed)
+		}
+		if len(file.MetaSerialized) > auditMaxMetadataLen {
+			add(AuditHugeMetadata, file.Filename, "has %d bytes of metadata", len(file.MetaSerialized))
+		}
+		if !file.FileInfo().IsDir() && file.Flags.Perm()&0111 != 0 {
+			add(AuditExecutable, file.Filename, "has executable mode %s", file.FileInfo().Mode().Perm())
+		}
+	}
+	return report
+}
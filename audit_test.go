@@ -0,0 +1,40 @@
+package phargo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAudit(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "../escape.php", data: []byte("<?php")},
+		testEntry{name: "run.sh", data: []byte("#!/bin/sh"), flags: 0755},
+		testEntry{name: "a.txt", data: []byte("AAAA")},
+		testEntry{name: "a.txt", data: []byte("AAAA")},
+	)
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	kinds := map[AuditKind]string{}
+	for _, finding := range Audit(file).Findings {
+		kinds[finding.Kind] = finding.Entry
+	}
+	for kind, entry := range map[AuditKind]string{
+		AuditWeakSignature: "",
+		AuditTraversalName: "../escape.php",
+		AuditExecutable:    "run.sh",
+		AuditDuplicateName: "a.txt",
+	} {
+		if got, ok := kinds[kind]; !ok || got != entry {
+			t.Errorf("Should report %s to %q, got %v", kind, entry, kinds)
+			return
+		}
+	}
+	if len(kinds) != 4 {
+		t.Errorf("Unexpected findings: %v", kinds)
+		return
+	}
+}
@@ -0,0 +1,112 @@
+package phargo
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// entryCache is LRU cache of decompressed entries content limited by bytes size
+type entryCache struct {
+	mutex    sync.Mutex
+	maxBytes int64
+	size     int64
+	order    *list.List              // Front is most recently used
+	entries  map[int64]*list.Element // Entry data offset to element
+}
+
+type cacheEntry struct {
+	offset int64
+	data   []byte
+}
+
+func newEntryCache(maxBytes int64) *entryCache {
+	return &entryCache{maxBytes: maxBytes, order: list.New(), entries: map[int64]*list.Element{}}
+}
+
+func (cache *entryCache) get(offset int64) ([]byte, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	element, ok := cache.entries[offset]
+	if !ok {
+		return nil, false
+	}
+	cache.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).data, true
+}
+
+func (cache *entryCache) put(offset int64, data []byte) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if _, ok := cache.entries[offset]; ok || int64(len(data)) > cache.maxBytes {
+		return
+	}
+
+	for cache.size+int64(len(data)) > cache.maxBytes {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*cacheEntry).offset)
+		cache.size -= int64(len(oldest.Value.(*cacheEntry).data))
+	}
+	cache.entries[offset] = cache.order.PushFront(&cacheEntry{offset, data})
+	cache.size += int64(len(data))
+}
+
+// open return cached file content, or read and cache it if fit in cache
+func (cache *entryCache) open(file File) (io.ReadCloser, error) {
+	if data, ok := cache.get(file.dataOffset); ok {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	r, err := file.openData()
+	if err != nil || file.SizeUncompressed > cache.maxBytes {
+		return r, err
+	}
+	defer r.Close()
+
+	buff := bytes.NewBuffer(make([]byte, 0, file.SizeUncompressed))
+	if _, err := copyBuffer(buff, r); err != nil {
+		return nil, err
+	}
+	cache.put(file.dataOffset, buff.Bytes())
+	return io.NopCloser(bytes.NewReader(buff.Bytes())), nil
+}
+
+// EnableCache keep up to maxBytes of decompressed entries in memory,
+// so repeated [File.Open] of same entry not decompress it again.
+// Zero or negative maxBytes disable cache.
+func (phar *Phar) EnableCache(maxBytes int64) {
+	var cache *entryCache
+	if maxBytes > 0 {
+		cache = newEntryCache(maxBytes)
+	}
+	for _, file := range phar.Files {
+		file.cache = cache
+	}
+}
+
+// LoadAll read whole archive into memory, so entries content and signature checks, like [Phar.Verify]
+// and [NewVerifyingReader], stay usable after underlying reader is closed, like short-lived HTTP responses.
+// Archive bigger than maxBytes return [ErrLimitExceeded] without reading, zero is unlimited.
+func (phar *Phar) LoadAll(maxBytes int64) error {
+	if _, loaded := phar.r.(*memReaderAt); loaded {
+		return nil
+	} else if phar.r == nil {
+		return fmt.Errorf("cannot load archive: reader not available")
+	} else if err := checkLimit("loaded archive", phar.size, maxBytes); err != nil {
+		return err
+	}
+
+	data := make([]byte, phar.size)
+	if n, err := phar.r.ReadAt(data, 0); n < len(data) {
+		return fmt.Errorf("cannot load archive: %w", err)
+	}
+	mem := &memReaderAt{data: data}
+	phar.r = mem
+	for _, file := range phar.Files {
+		file.metadataOpen = mem
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package phargo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "a.txt", data: []byte("AAAA")},
+		testEntry{name: "b.txt", data: []byte("BBBB")},
+	)
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	file.EnableCache(6)
+	for _, name := range []string{"a.txt", "b.txt", "a.txt"} {
+		content, err := fs.ReadFile(file, name)
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		} else if string(content) != strings.Repeat(strings.ToUpper(name[:1]), 4) {
+			t.Errorf("Wrong %s content: %q", name, content)
+			return
+		}
+	}
+
+	cache := file.Files[0].cache
+	if cache.size > 6 || len(cache.entries) != 1 {
+		t.Errorf("Cache over budget: %d bytes in %d entries", cache.size, len(cache.entries))
+		return
+	} else if _, ok := cache.get(file.Files[0].dataOffset); !ok {
+		t.Error("Last opened entry not cached")
+		return
+	}
+}
+
+func TestLoadAll(t *testing.T) {
+	data := signPhar(buildPhar(
+		testEntry{name: "a.txt", data: []byte("AAAA")},
+		testEntry{name: "b.txt", data: []byte("BBBB")},
+	))
+	name := filepath.Join(t.TempDir(), "load.phar")
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Error(err)
+		return
+	}
+	osFile, err := os.Open(name)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	file, err := NewReaderFromFile(osFile)
+	if err != nil {
+		osFile.Close()
+		t.Error("Got error", err)
+		return
+	}
+
+	if err := file.LoadAll(int64(len(data) - 1)); !errors.Is(err, ErrLimitExceeded) {
+		osFile.Close()
+		t.Errorf("Should get ErrLimitExceeded, got %v", err)
+		return
+	} else if err := file.LoadAll(int64(len(data))); err != nil {
+		osFile.Close()
+		t.Error("Got error", err)
+		return
+	}
+
+	// Source is closed, content and signature must come from memory
+	osFile.Close()
+	if content, err := fs.ReadFile(file, "b.txt"); err != nil || string(content) != "BBBB" {
+		t.Errorf("Wrong b.txt content after LoadAll: %q, %v", content, err)
+		return
+	} else if err := file.Verify(VerifyScopeAll); err != nil {
+		t.Error("Verify after close:", err)
+		return
+	}
+
+	verifier, err := NewVerifyingReader(file)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	for {
+		if _, err := verifier.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Error("Got error", err)
+			return
+		} else if _, err := io.Copy(io.Discard, verifier); err != nil {
+			t.Error("Got error", err)
+			return
+		}
+	}
+	if err := verifier.Close(); err != nil {
+		t.Error("VerifyingReader after close:", err)
+		return
+	}
+}
@@ -0,0 +1,159 @@
+// Package cachedreaderat adapt chunk fetch functions of remote storages, like HTTP Range
+// requests or S3 GetObject with Range, to caching [io.ReaderAt].
+//
+// Read-ahead follow phargo access pattern: single reads at tail to signature and
+// sequential reads at head to stub and manifest, doubling fetched blocks while reads
+// stay sequential.
+package cachedreaderat
+
+import (
+	"container/list"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Cache defaults, remote file is read in blocks and recent blocks kept in memory
+const (
+	DefaultBlockSize    = 64 * 1024
+	DefaultMaxBlocks    = 256
+	DefaultMaxReadAhead = 16
+)
+
+// Fetcher read n bytes at offset from remote file, returning all n bytes or error
+type Fetcher interface {
+	Fetch(off, n int64) ([]byte, error)
+}
+
+// FetchFunc adapt function to [Fetcher]
+type FetchFunc func(off, n int64) ([]byte, error)
+
+func (fn FetchFunc) Fetch(off, n int64) ([]byte, error) { return fn(off, n) }
+
+// ReaderAt read remote file through [Fetcher], caching blocks.
+// It is safe for concurrent use.
+type ReaderAt struct {
+	BlockSize    int64 // Bytes fetched per block
+	MaxBlocks    int   // Blocks kept in cache
+	MaxReadAhead int64 // Max blocks fetched after sequential reads
+
+	fetcher   Fetcher
+	size      int64
+	mutex     sync.Mutex
+	order     *list.List              // Front is most recently used
+	blocks    map[int64]*list.Element // Block index to element
+	nextBlock int64                   // Block after last fetch, to detect sequential reads
+	readAhead int64                   // Blocks fetched after missing run
+}
+
+type cacheBlock struct {
+	index int64
+	data  []byte
+}
+
+// New return [ReaderAt] of size bytes fetched from fetcher with default cache
+func New(fetcher Fetcher, size int64) *ReaderAt {
+	return &ReaderAt{
+		BlockSize:    DefaultBlockSize,
+		MaxBlocks:    DefaultMaxBlocks,
+		MaxReadAhead: DefaultMaxReadAhead,
+		fetcher:      fetcher,
+		size:         size,
+		order:        list.New(),
+		blocks:       map[int64]*list.Element{},
+		nextBlock:    -1,
+	}
+}
+
+// Size return remote file size
+func (r *ReaderAt) Size() int64 { return r.size }
+
+func (r *ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("cachedreaderat: negative offset")
+	} else if off >= r.size {
+		return 0, io.EOF
+	}
+
+	end := min(off+int64(len(p)), r.size)
+	n, last := 0, (end-1)/r.BlockSize
+	for index := off / r.BlockSize; index <= last; {
+		data, ok := r.block(index)
+		runEnd := index
+		if !ok {
+			// Fetch run of missing blocks in one request
+			for runEnd < last {
+				if _, ok := r.block(runEnd + 1); ok {
+					break
+				}
+				runEnd++
+			}
+			var err error
+			if data, err = r.fetch(index, runEnd); err != nil {
+				return n, err
+			}
+		}
+		n += copy(p[n:], data[off+int64(n)-index*r.BlockSize:])
+		index = runEnd + 1
+	}
+
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// block return cached block, marking it as recently used
+func (r *ReaderAt) block(index int64) ([]byte, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	element, ok := r.blocks[index]
+	if !ok {
+		return nil, false
+	}
+	r.order.MoveToFront(element)
+	return element.Value.(*cacheBlock).data, true
+}
+
+// fetch request blocks from first to last with read-ahead, add them to cache
+// and return data from first to last
+func (r *ReaderAt) fetch(first, last int64) ([]byte, error) {
+	r.mutex.Lock()
+	if first == r.nextBlock {
+		r.readAhead = min(max(r.readAhead*2, 1), r.MaxReadAhead)
+	} else {
+		r.readAhead = 0
+	}
+	lastBlock := (r.size - 1) / r.BlockSize
+	fetchLast := min(last+r.readAhead, lastBlock)
+	r.nextBlock = fetchLast + 1
+	r.mutex.Unlock()
+
+	start, end := first*r.BlockSize, min((fetchLast+1)*r.BlockSize, r.size)
+	data, err := r.fetcher.Fetch(start, end-start)
+	if err != nil {
+		return nil, err
+	} else if int64(len(data)) != end-start {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	for index := first; index <= fetchLast; index++ {
+		blockStart := (index - first) * r.BlockSize
+		r.put(index, data[blockStart:min(blockStart+r.BlockSize, int64(len(data)))])
+	}
+	return data[:min((last-first+1)*r.BlockSize, int64(len(data)))], nil
+}
+
+func (r *ReaderAt) put(index int64, data []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.blocks[index]; ok {
+		return
+	}
+	for r.order.Len() >= max(r.MaxBlocks, 1) {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.blocks, oldest.Value.(*cacheBlock).index)
+	}
+	r.blocks[index] = r.order.PushFront(&cacheBlock{index, data})
+}
@@ -0,0 +1,70 @@
+package cachedreaderat
+
+import (
+	"bytes"
+	"io"
+	"math/rand/v2"
+	"os"
+	"testing"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+// countFetcher read from data counting fetches
+type countFetcher struct {
+	data    []byte
+	fetches int
+}
+
+func (fetcher *countFetcher) Fetch(off, n int64) ([]byte, error) {
+	fetcher.fetches++
+	return bytes.Clone(fetcher.data[off : off+n]), nil
+}
+
+func TestReaderAt(t *testing.T) {
+	data := make([]byte, 100_000)
+	for index := range data {
+		data[index] = byte(rand.IntN(256))
+	}
+
+	r := New(&countFetcher{data: data}, int64(len(data)))
+	r.BlockSize, r.MaxBlocks = 1000, 8
+	for range 500 {
+		off := rand.Int64N(int64(len(data)))
+		buff := make([]byte, rand.IntN(12_000))
+		n, err := r.ReadAt(buff, off)
+		if err != nil && !(err == io.EOF && off+int64(len(buff)) > int64(len(data))) {
+			t.Errorf("Got error reading %d bytes at %d: %s", len(buff), off, err)
+			return
+		} else if !bytes.Equal(buff[:n], data[off:off+int64(n)]) {
+			t.Errorf("Wrong data reading %d bytes at %d", len(buff), off)
+			return
+		}
+	}
+}
+
+func TestReadAhead(t *testing.T) {
+	data, err := os.ReadFile("../testdata/PocketMine-MP_1.4.1.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+
+	fetcher := &countFetcher{data: data}
+	r := New(fetcher, int64(len(data)))
+	r.BlockSize = 4096
+	pharInfo, err := phargo.NewReaderWithOptions(r, r.Size(), phargo.ReaderOptions{Trusted: true})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(pharInfo.Files) == 0 {
+		t.Error("No files")
+		return
+	}
+
+	// Without read-ahead each block of stub and manifest is one fetch
+	if blocks := (pharInfo.Manifest.ContentOffset() + r.BlockSize - 1) / r.BlockSize; int64(fetcher.fetches) >= blocks {
+		t.Errorf("Read-ahead should fetch less than %d times, got %d", blocks, fetcher.fetches)
+		return
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+func aliasCommand(args []string) error {
+	flags := newFlagSet("alias")
+	output := flags.String("o", "", "Archive to write with new alias, unsupported as phargo only read archives")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) == 0 || len(args) > 2 {
+		return usageError(flags, "file.phar|URL")
+	} else if len(args) == 2 || *output != "" {
+		// Set is declined, new alias change manifest length and signature, phargo has no archive writer
+		return &cliError{Code: exitUsage, Message: "changing alias needs archive writer, phargo only read archives", File: args[0]}
+	}
+
+	pharInfo, file, err := openPharWithOptions(args[0], phargo.ReaderOptions{Trusted: true})
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Manifest alias, or alias registered by stub Phar::mapPhar call
+	alias := string(pharInfo.Manifest.Alias)
+	if alias == "" {
+		alias = pharInfo.Stub.Alias
+	}
+	fmt.Fprintln(os.Stdout, alias)
+	return nil
+}
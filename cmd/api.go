@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+// apiEntry is entry in api json responses
+type apiEntry struct {
+	Name           string    `json:"name"`
+	Size           int64     `json:"size"`
+	CompressedSize int64     `json:"compressed_size"`
+	Compression    string    `json:"compression"`
+	Mode           string    `json:"mode"`
+	Modified       time.Time `json:"modified"`
+	CRC            uint32    `json:"crc"`
+	Metadata       string    `json:"metadata,omitempty"`
+}
+
+// apiManifest is archive details in api json responses
+type apiManifest struct {
+	Version   string            `json:"version"`
+	Alias     string            `json:"alias,omitempty"`
+	Metadata  string            `json:"metadata,omitempty"`
+	Entries   int               `json:"entries"`
+	Signature *phargo.Signature `json:"signature,omitempty"`
+	Stub      phargo.StubInfo   `json:"stub"`
+	Issues    []phargo.Issue    `json:"issues,omitempty"`
+}
+
+// apiVerify is /verify response
+type apiVerify struct {
+	OK    bool      `json:"ok"`
+	Error *cliError `json:"error,omitempty"`
+}
+
+// apiHandler serve archive details and files as json api, path is reopened to /verify
+func apiHandler(path string, pharInfo *phargo.Phar) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /manifest", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, apiManifest{
+			Version:   pharInfo.Manifest.Version,
+			Alias:     string(pharInfo.Manifest.Alias),
+			Metadata:  string(pharInfo.Manifest.Metadata),
+			Entries:   len(pharInfo.Files),
+			Signature: pharInfo.Signature,
+			Stub:      pharInfo.Stub,
+			Issues:    pharInfo.Issues,
+		})
+	})
+
+	mux.HandleFunc("GET /files", func(w http.ResponseWriter, r *http.Request) {
+		entries := make([]apiEntry, 0, len(pharInfo.Files))
+		for _, file := range pharInfo.Files {
+			compression := "none"
+			switch {
+			case file.Flags.Compression() == phargo.EntryCompressedGzip:
+				compression = "gzip"
+			case file.Flags.Compression() == phargo.EntryCompressedBzip2:
+				compression = "bzip2"
+			}
+			entries = append(entries, apiEntry{
+				Name:           file.Filename,
+				Size:           file.SizeUncompressed,
+				CompressedSize: file.SizeCompressed,
+				Compression:    compression,
+				Mode:           file.FileInfo().Mode().String(),
+				Modified:       file.Timestamp.UTC(),
+				CRC:            file.CRC,
+				Metadata:       string(file.MetaSerialized),
+			})
+		}
+		writeJSON(w, http.StatusOK, entries)
+	})
+
+	mux.HandleFunc("GET /files/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("path")
+		if stat, err := fs.Stat(pharInfo, name); err != nil {
+			writeJSON(w, http.StatusNotFound, &cliError{Code: exitIO, Message: err.Error(), File: path, Entry: name})
+			return
+		} else if stat.IsDir() {
+			writeJSON(w, http.StatusBadRequest, &cliError{Code: exitUsage, Message: name + " is a folder", File: path, Entry: name})
+			return
+		}
+		http.ServeFileFS(w, r, pharInfo, name)
+	})
+
+	mux.HandleFunc("GET /verify", func(w http.ResponseWriter, r *http.Request) {
+		// Parse again with signature and CRC checks
+		_, file, err := openPhar(path)
+		if err != nil {
+			cliErr, _ := err.(*cliError)
+			writeJSON(w, http.StatusOK, apiVerify{Error: cliErr})
+			return
+		}
+		file.Close()
+		writeJSON(w, http.StatusOK, apiVerify{OK: true})
+	})
+	return mux
+}
+
+// writeJSON write value as json response with status
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
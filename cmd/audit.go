@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+func auditCommand(args []string) error {
+	flags := newFlagSet("audit")
+	jsonOutput := flags.Bool("json", false, "Print findings as json")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 1 {
+		return usageError(flags, "[--json] file.phar")
+	}
+
+	pharInfo, file, err := openPhar(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report := phargo.Audit(pharInfo)
+	if *jsonOutput {
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			return fileError(args[0], err)
+		}
+	} else if !report.OK() {
+		table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "FINDING\tENTRY\tDETAIL")
+		for _, finding := range report.Findings {
+			fmt.Fprintf(table, "%s\t%s\t%s\n", finding.Kind, finding.Entry, finding.Detail)
+		}
+		table.Flush()
+	}
+
+	if !report.OK() {
+		return &cliError{Code: exitAudit, Message: fmt.Sprintf("%s has %d risky traits", args[0], len(report.Findings)), File: args[0]}
+	}
+	fmt.Fprintf(os.Stderr, "%s has no risky traits\n", args[0])
+	return nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+// checkResult is validation result of one phar file, checks not run are "-"
+type checkResult struct {
+	Path            string `json:"file"`
+	Status          string `json:"status"` // ok or exit code name of first failed check
+	Format          string `json:"format"`
+	Version         string `json:"version"`
+	Signature       string `json:"signature"`        // Signature algorithm or unsigned
+	SignatureStatus string `json:"signature_status"` // verified, not verified, unsigned or bad
+	CRC             string `json:"crc"`              // ok, bad or error if entry can't be read
+	BadCRC          int    `json:"bad_crc"`          // Entries with bad CRC
+	Entries         int    `json:"entries"`
+	Message         string `json:"error,omitempty"`
+	Error           error  `json:"-"`
+}
+
+// checkReport is json output of check command
+type checkReport struct {
+	Checked  int            `json:"checked"`
+	Failed   int            `json:"failed"`
+	Status   map[string]int `json:"status"` // Archives count by status
+	Archives []checkResult  `json:"archives"`
+}
+
+func checkCommand(args []string) error {
+	flags := newFlagSet("check")
+	recursive := flags.Bool("recursive", false, "Search phar files in subdirectories")
+	jobs := flags.Int("jobs", runtime.NumCPU(), "Number of archives validated concurrently")
+	jsonOutput := flags.Bool("json", false, "Print results and totals as json")
+	keyPath := flags.String("key", "", "Public key to OpenSSL signatures, default each archive path with .pubkey suffix")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) == 0 {
+		return usageError(flags, "[--recursive] [--jobs N] [--json] [--key app.phar.pubkey] dir/ | file.phar...")
+	}
+
+	var paths []string
+	for _, arg := range args {
+		found, err := findPhars(arg, *recursive)
+		if err != nil {
+			return fileError(arg, err)
+		}
+		paths = append(paths, found...)
+	}
+
+	results := checkPhars(paths, *keyPath, max(*jobs, 1))
+	report := checkReport{Checked: len(results), Status: map[string]int{}, Archives: results}
+	var firstErr error
+	for _, result := range results {
+		report.Status[result.Status]++
+		if result.Error != nil {
+			report.Failed++
+			if firstErr == nil {
+				firstErr = result.Error
+			}
+		}
+	}
+
+	if *jsonOutput {
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			return err
+		}
+	} else {
+		table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(table, "FILE\tSTATUS\tFORMAT\tVERSION\tSIGNATURE\tSIG STATUS\tCRC\tENTRIES\tERROR")
+		for _, result := range results {
+			crc := result.CRC
+			if result.BadCRC > 0 {
+				crc = fmt.Sprintf("%s (%d)", crc, result.BadCRC)
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n", result.Path, result.Status, result.Format, result.Version,
+				result.Signature, result.SignatureStatus, crc, result.Entries, result.Message)
+		}
+		table.Flush()
+		fmt.Fprintf(os.Stdout, "\n%d checked, %d failed\n", report.Checked, report.Failed)
+	}
+
+	if firstErr != nil {
+		return &cliError{Code: errorCode(firstErr), Message: fmt.Sprintf("%d of %d archives failed check", report.Failed, report.Checked), Err: firstErr}
+	}
+	return nil
+}
+
+// checkOpenSSL return signature status of OpenSSL signed archive, archives
+// without keyPath and .pubkey file are not verified
+func checkOpenSSL(path, keyPath string) (string, error) {
+	if keyPath == "" {
+		keyPath = phargo.PublicKeyPath(path)
+		if _, err := os.Stat(keyPath); errors.Is(err, fs.ErrNotExist) {
+			return "not verified", nil
+		}
+	}
+
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return "not verified", fileError(path, fmt.Errorf("cannot read public key to OpenSSL signature: %w", err))
+	}
+	key, err := phargo.ParsePublicKey(data)
+	if err != nil {
+		return "not verified", &cliError{Code: exitSignature, Message: fmt.Sprintf("%s: %s", keyPath, err), File: path, Err: err}
+	} else if err := verifyOpenSSL(path, key); err != nil {
+		return "bad", err
+	}
+	return "verified", nil
+}
+
+// findPhars return path if is file, or *.phar files inside directory
+func findPhars(root string, recursive bool) ([]string, error) {
+	stat, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	} else if !stat.IsDir() {
+		return []string{root}, nil
+	}
+
+	var paths []string
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if entry.IsDir() {
+			if path != root && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.EqualFold(filepath.Ext(path), ".phar") {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// checkPhars parse and validate phar files with jobs workers, results keep paths order
+func checkPhars(paths []string, keyPath string, jobs int) []checkResult {
+	results := make([]checkResult, len(paths))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(jobs, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				results[index] = checkPhar(paths[index], keyPath)
+			}
+		}()
+	}
+
+	for index := range paths {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// checkPhar run format, signature and CRC checks, archives with bad signature
+// are parsed again without checks to still report version and CRC.
+// OpenSSL signatures are checked with keyPath, or archive .pubkey file if exists
+func checkPhar(path, keyPath string) (result checkResult) {
+	result = checkResult{Path: path, Format: "-", Version: "-", Signature: "-", SignatureStatus: "-", CRC: "-"}
+	defer func() {
+		result.Status = "ok"
+		if result.Error != nil {
+			result.Status, result.Message = errorCode(result.Error).String(), result.Error.Error()
+		}
+	}()
+
+	pharInfo, file, err := openPharWithOptions(path, phargo.ReaderOptions{SkipCRC: true})
+	if err != nil && errorCode(err) == exitSignature {
+		result.Error, result.SignatureStatus = err, "bad"
+		pharInfo, file, err = openPharWithOptions(path, phargo.ReaderOptions{Trusted: true})
+	}
+	if err != nil {
+		if result.Error == nil {
+			result.Error = err
+		}
+		return result
+	}
+	defer file.Close()
+
+	result.Format, result.Version, result.Entries = "phar", pharInfo.Manifest.Version, len(pharInfo.Files)
+	result.Signature = "unsigned"
+	if pharInfo.Signature != nil {
+		result.Signature = pharInfo.Signature.Signature.String()
+	}
+	if result.SignatureStatus == "-" {
+		switch {
+		case pharInfo.Signature == nil:
+			result.SignatureStatus = "unsigned"
+		case pharInfo.Signature.Signature&phargo.SignatureOpenSSL != 0:
+			result.SignatureStatus, result.Error = checkOpenSSL(path, keyPath)
+		default:
+			result.SignatureStatus = "verified"
+		}
+	}
+
+	// Entries not readable, like truncated or bad compressed data, are error without CRC mismatch
+	result.CRC = "ok"
+	for _, entry := range pharInfo.Files {
+		if err := entry.VerifyCRC(); err != nil {
+			if errors.Is(err, phargo.ErrBadCRC) {
+				result.CRC = "bad"
+				result.BadCRC++
+			} else if result.CRC == "ok" {
+				result.CRC = "error"
+			}
+			if result.Error == nil {
+				result.Error = entryError(path, entry.Filename, err)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+// Process exit codes, stable across releases
+const (
+	exitOK        exitCode = iota // Success
+	exitError                     // Unclassified failure
+	exitUsage                     // Invalid command line
+	exitIO                        // Cannot read or write files
+	exitFormat                    // Archive structure is invalid
+	exitSignature                 // Archive signature not match
+	exitCRC                       // Entry content not match CRC
+	exitMismatch                  // Extracted files differ from archive
+	exitAudit                     // Audit found risky traits
+)
+
+var (
+	errorFormat = flag.String("error-format", "text", "Error output format: text or json")
+
+	exitCodeName = map[exitCode]string{
+		exitOK:        "ok",
+		exitError:     "error",
+		exitUsage:     "usage",
+		exitIO:        "io_error",
+		exitFormat:    "bad_format",
+		exitSignature: "bad_signature",
+		exitCRC:       "bad_crc",
+		exitMismatch:  "tree_mismatch",
+		exitAudit:     "audit_findings",
+	}
+)
+
+type exitCode int
+
+func (code exitCode) String() string {
+	if str, ok := exitCodeName[code]; ok {
+		return str
+	}
+	return "unknown"
+}
+
+func (code exitCode) MarshalText() ([]byte, error) { return []byte(code.String()), nil }
+
+// cliError is a error with archive and entry context
+type cliError struct {
+	Code    exitCode `json:"code"`
+	Message string   `json:"message"`
+	File    string   `json:"file,omitempty"`
+	Entry   string   `json:"entry,omitempty"`
+	Err     error    `json:"-"`
+}
+
+func (err *cliError) Error() string { return err.Message }
+func (err *cliError) Unwrap() error { return err.Err }
+
+// fileError attach phar file path to error
+func fileError(file string, err error) error {
+	return entryError(file, "", err)
+}
+
+// entryError attach phar file path and entry name to error
+func entryError(file, entry string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &cliError{Code: errorCode(err), Message: err.Error(), File: file, Entry: entry, Err: err}
+}
+
+// errorCode classify error to exit code
+func errorCode(err error) exitCode {
+	var cliErr *cliError
+	var pathErr *fs.PathError
+	switch {
+	case errors.As(err, &cliErr):
+		return cliErr.Code
+	case errors.Is(err, phargo.ErrBadCRC):
+		return exitCRC
+	case errors.Is(err, phargo.ErrInvalidSignature), errors.Is(err, phargo.ErrGBMB), errors.Is(err, phargo.ErrSignatureSize):
+		return exitSignature
+	case errors.Is(err, phargo.ErrUnsafePath), errors.Is(err, phargo.ErrWindowsName), errors.Is(err, phargo.ErrInvalidName),
+		errors.Is(err, phargo.ErrBadManifest), errors.Is(err, phargo.ErrNoHaltCompiler), errors.Is(err, phargo.ErrLimitExceeded), errors.Is(err, phargo.ErrTruncated), errors.Is(err, phargo.ErrDuplicateEntry),
+		errors.Is(err, phargo.ErrUncompressedSize):
+		return exitFormat
+	case errors.As(err, &pathErr):
+		return exitIO
+	default:
+		return exitError
+	}
+}
+
+// fail print error in selected format and exit with error code
+func fail(err error) {
+	var cliErr *cliError
+	if !errors.As(err, &cliErr) {
+		cliErr = &cliError{Code: errorCode(err), Message: err.Error(), Err: err}
+	}
+
+	if *errorFormat == "json" {
+		json.NewEncoder(os.Stderr).Encode(cliErr)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s\n", cliErr.Message)
+	}
+	os.Exit(int(cliErr.Code))
+}
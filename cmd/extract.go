@@ -0,0 +1,164 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+func extractCommand(args []string) error {
+	flags := newFlagSet("extract")
+	output := flags.String("o", ".", "Folder to extract files")
+	toTar := flags.String("to-tar", "", "Write files to tar archive, - to stdout")
+	toZip := flags.String("to-zip", "", "Write files to zip archive, - to stdout")
+	windowsNames := flags.String("windows-names", "auto", "Names not valid on Windows: auto, reject or mangle")
+	absolutePaths := flags.String("absolute-paths", "reject", "Entries with absolute names: reject, strip or preserve")
+	noFollowSymlinks := flags.Bool("no-follow-symlinks", false, "Refuse to write through symlinks already in output folder")
+	overwrite := flags.String("overwrite", "replace", "Files already in output folder: replace, skip or reject")
+	include := flags.String("include", "", "Extract only entries matching glob pattern, or inside matching folders")
+	preserveMode := flags.Bool("preserve-mode", false, "Set permissions from entries")
+	preserveTimes := flags.Bool("preserve-times", false, "Set modification times from entries")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 1 || (*toTar != "" && *toZip != "") {
+		return usageError(flags, "file.phar [-o dir [--windows-names auto|reject|mangle] [--absolute-paths reject|strip|preserve] [--no-follow-symlinks] [--overwrite replace|skip|reject] [--include glob] [--preserve-mode] [--preserve-times] | --to-tar out.tar | --to-zip out.zip]")
+	}
+
+	policy, ok := windowsNamePolicies[*windowsNames]
+	if !ok {
+		return usageError(flags, "--windows-names must be auto, reject or mangle")
+	}
+	absolutePolicy, ok := absolutePathPolicies[*absolutePaths]
+	if !ok {
+		return usageError(flags, "--absolute-paths must be reject, strip or preserve")
+	}
+	overwritePolicy, ok := overwritePolicies[*overwrite]
+	if !ok {
+		return usageError(flags, "--overwrite must be replace, skip or reject")
+	} else if _, err := path.Match(*include, ""); err != nil {
+		return usageError(flags, "--include must be valid glob pattern")
+	}
+
+	pharInfo, file, err := openPhar(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch {
+	case *toTar != "":
+		return writeArchive(args[0], *toTar, func(w io.Writer) error { return extractTar(args[0], pharInfo, w) })
+	case *toZip != "":
+		return writeArchive(args[0], *toZip, func(w io.Writer) error { return extractZip(args[0], pharInfo, w) })
+	default:
+		opts := []phargo.ExtractOption{phargo.WithWindowsNames(policy), phargo.WithAbsolutePaths(absolutePolicy), phargo.WithOverwrite(overwritePolicy)}
+		if *noFollowSymlinks {
+			opts = append(opts, phargo.NoFollowSymlinks())
+		}
+		if *include != "" {
+			opts = append(opts, phargo.WithFilter(func(file *phargo.File) bool { return matchInclude(*include, file.Filename) }))
+		}
+		if *preserveMode {
+			opts = append(opts, phargo.PreserveMode())
+		}
+		if *preserveTimes {
+			opts = append(opts, phargo.PreserveTimes())
+		}
+		return extractDir(args[0], pharInfo, *output, opts...)
+	}
+}
+
+// writeArchive create output file, or stdout if "-", and call write to fill it
+func writeArchive(pharPath, output string, write func(w io.Writer) error) error {
+	if output == "-" {
+		return write(os.Stdout)
+	}
+
+	w, err := os.Create(output)
+	if err != nil {
+		return fileError(pharPath, fmt.Errorf("cannot create %s file: %w", output, err))
+	}
+	defer w.Close()
+	if err := write(w); err != nil {
+		return err
+	}
+	return fileError(pharPath, w.Close())
+}
+
+var windowsNamePolicies = map[string]phargo.WindowsNamePolicy{
+	"auto":   phargo.WindowsNamesAuto,
+	"reject": phargo.WindowsNamesReject,
+	"mangle": phargo.WindowsNamesMangle,
+}
+
+var absolutePathPolicies = map[string]phargo.AbsolutePathPolicy{
+	"reject":   phargo.AbsolutePathsReject,
+	"strip":    phargo.AbsolutePathsStrip,
+	"preserve": phargo.AbsolutePathsPreserve,
+}
+
+var overwritePolicies = map[string]phargo.OverwritePolicy{
+	"replace": phargo.OverwriteReplace,
+	"skip":    phargo.OverwriteSkip,
+	"reject":  phargo.OverwriteReject,
+}
+
+// matchInclude report if name or any of its parent folders match glob pattern
+func matchInclude(pattern, name string) bool {
+	for name = strings.Trim(name, "/"); name != "." && name != "/"; name = path.Dir(name) {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// extractDir write phar files to folder
+func extractDir(pharPath string, pharInfo *phargo.Phar, output string, opts ...phargo.ExtractOption) error {
+	opts = append(opts, phargo.OnExtracted(func(_ *phargo.File, path string) {
+		println(path)
+	}))
+
+	err := phargo.Extract(pharInfo, output, opts...)
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) && pathErr.Op == "extract" {
+		return entryError(pharPath, pathErr.Path, err)
+	}
+	return fileError(pharPath, err)
+}
+
+// extractTar write phar files to tar stream
+func extractTar(pharPath string, pharInfo *phargo.Phar, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	if err := pharInfo.WriteTar(tw); err != nil {
+		return archiveError(pharPath, err)
+	}
+	return fileError(pharPath, tw.Close())
+}
+
+// extractZip write phar files to zip stream
+func extractZip(pharPath string, pharInfo *phargo.Phar, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	if err := pharInfo.WriteZip(zw); err != nil {
+		return archiveError(pharPath, err)
+	}
+	return fileError(pharPath, zw.Close())
+}
+
+// archiveError attach entry name of [*fs.PathError] returned by phargo to error
+func archiveError(pharPath string, err error) error {
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return entryError(pharPath, pathErr.Path, err)
+	}
+	return fileError(pharPath, err)
+}
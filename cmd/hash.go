@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func hashCommand(args []string) error {
+	flags := newFlagSet("hash")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) == 0 {
+		return usageError(flags, "file.phar|URL...")
+	}
+
+	for _, arg := range args {
+		pharInfo, file, err := openPhar(arg)
+		if err != nil {
+			return err
+		}
+		digest, err := pharInfo.ContentDigest()
+		file.Close()
+		if err != nil {
+			return fileError(arg, err)
+		}
+		fmt.Fprintf(os.Stdout, "%x  %s\n", digest, arg)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+// pharSummary is json output of info command
+type pharSummary struct {
+	File      string                   `json:"file"`
+	Version   string                   `json:"version"`
+	Alias     string                   `json:"alias,omitempty"`
+	Stub      phargo.StubInfo          `json:"stub"`
+	Entries   int                      `json:"entries"`
+	Signature string                   `json:"signature"`
+	Composer  string                   `json:"composer,omitempty"` // composer.lock or installed.json path
+	Packages  []phargo.ComposerPackage `json:"packages,omitempty"`
+
+	Compatibility *phargo.CompatibilityReport `json:"compatibility"`
+}
+
+func infoCommand(args []string) error {
+	flags := newFlagSet("info")
+	jsonOutput := flags.Bool("json", false, "Print info as json")
+	flags.Bool("php-compat", true, "Deprecated, PHP compatibility is always printed")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 1 {
+		return usageError(flags, "[--json] file.phar|URL")
+	}
+
+	pharInfo, file, err := openPharWithOptions(args[0], phargo.ReaderOptions{Trusted: true})
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	summary := pharSummary{
+		File:      args[0],
+		Version:   pharInfo.Manifest.Version,
+		Alias:     string(pharInfo.Manifest.Alias),
+		Stub:      pharInfo.Stub,
+		Entries:   len(pharInfo.Files),
+		Signature: "unsigned",
+	}
+	if pharInfo.Signature != nil {
+		summary.Signature = pharInfo.Signature.Signature.String()
+	}
+	if summary.Composer, err = pharInfo.ComposerFile(); err == nil {
+		if summary.Packages, err = pharInfo.ComposerPackages(); err != nil {
+			return entryError(args[0], summary.Composer, err)
+		}
+	} else if !errors.Is(err, phargo.ErrNoComposer) {
+		return fileError(args[0], err)
+	}
+
+	report := phargo.Compatibility(pharInfo)
+	summary.Compatibility = &report
+
+	if *jsonOutput {
+		if err := json.NewEncoder(os.Stdout).Encode(summary); err != nil {
+			return fileError(args[0], err)
+		}
+		return nil
+	}
+
+	fmt.Fprint(os.Stdout, pharInfo.Summary())
+	fmt.Fprintf(os.Stdout, "\nminimum php version: %s\n", report.MinPHPVersion)
+	fmt.Fprintf(os.Stdout, "php extensions: %s\n", strings.Join(report.Extensions, ", "))
+	for _, req := range report.Requirements {
+		fmt.Fprintf(os.Stdout, "  %s\n", req)
+	}
+	if summary.Composer == "" {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stdout, "\npackages from %s:\n", summary.Composer)
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "PACKAGE\tVERSION\tLICENSE\tDEV")
+	for _, pkg := range summary.Packages {
+		license := "-"
+		if len(pkg.License) > 0 {
+			license = pkg.License[0]
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%t\n", pkg.Name, pkg.Version, license, pkg.Dev)
+	}
+	table.Flush()
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+func lsCommand(args []string) error {
+	flags := newFlagSet("ls")
+	verify := flags.Bool("verify", false, "Check signature and CRC, reading whole archive")
+	format := flags.String("format", "table", "Listing format: table, or phar to match PHP phar.phar list")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 1 || (*format != "table" && *format != "phar") {
+		return usageError(flags, "[--verify] [--format table|phar] file.phar|URL")
+	}
+
+	// Trusted read only stub, manifest and signature, so remote archives are not downloaded
+	pharInfo, file, err := openPharWithOptions(args[0], phargo.ReaderOptions{Trusted: !*verify})
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if *format == "phar" {
+		base := args[0]
+		if !strings.Contains(base, "://") {
+			if base, err = filepath.Abs(base); err != nil {
+				return fileError(args[0], err)
+			}
+			base = filepath.ToSlash(base)
+		}
+		pharList(os.Stdout, "phar://"+base, pharInfo.Files)
+		return nil
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "MODE\tSIZE\tMODIFIED\tNAME")
+	for _, entry := range pharInfo.Files {
+		info := entry.FileInfo()
+		fmt.Fprintf(table, "%s\t%d\t%s\t%s\n", info.Mode(), info.Size(), info.ModTime().UTC().Format(time.DateTime), entry.Filename)
+	}
+	table.Flush()
+
+	switch {
+	case pharInfo.Signature == nil:
+		fmt.Fprintln(os.Stdout, "signature: unsigned")
+	case *verify:
+		fmt.Fprintf(os.Stdout, "signature: %s %x (verified)\n", pharInfo.Signature.Signature, pharInfo.Signature.Hash)
+	default:
+		fmt.Fprintf(os.Stdout, "signature: %s %x (not verified)\n", pharInfo.Signature.Signature, pharInfo.Signature.Hash)
+	}
+	return nil
+}
+
+// pharList print entries tree like `php phar.phar list -f app.phar`, with
+// sorted folders listed before their entries and "phar://" prefixed paths:
+//
+//	|-phar:///app.phar/index.php
+//	\-phar:///app.phar/src
+//	  \-phar:///app.phar/src/app.php
+func pharList(w io.Writer, base string, files []*phargo.File) {
+	children, seen := map[string][]string{}, map[string]bool{}
+	var add func(name string)
+	add = func(name string) {
+		if name == "" || name == "." || seen[name] {
+			return
+		}
+		seen[name] = true
+		parent := path.Dir(name)
+		if parent == "." {
+			parent = ""
+		}
+		add(parent)
+		children[parent] = append(children[parent], name)
+	}
+	for _, file := range files {
+		add(strings.Trim(file.Filename, "/"))
+	}
+
+	var walk func(dir, prefix string)
+	walk = func(dir, prefix string) {
+		names := children[dir]
+		slices.Sort(names)
+		for index, name := range names {
+			branch, next := "|-", "| "
+			if index == len(names)-1 {
+				branch, next = `\-`, "  "
+			}
+			fmt.Fprintf(w, "%s%s%s/%s\n", prefix, branch, base, name)
+			walk(name, prefix+next)
+		}
+	}
+	walk("", "")
+}
@@ -2,13 +2,16 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
+	"strings"
 
 	"github.com/Sirherobrine23/phargo"
+	"github.com/Sirherobrine23/phargo/httpreaderat"
+	"github.com/Sirherobrine23/phargo/multireaderat"
 )
 
 var (
@@ -16,59 +19,141 @@ var (
 	extractPath  = flag.String("extract", "", "Folder to extract files")
 )
 
-func main() {
-	flag.Parse()
+// Subcommands, called with arguments after command name
+var commands = map[string]func(args []string) error{
+	"alias":         aliasCommand,
+	"audit":         auditCommand,
+	"check":         checkCommand,
+	"export-pubkey": exportPubkeyCommand,
+	"extract":       extractCommand,
+	"hash":          hashCommand,
+	"info":          infoCommand,
+	"ls":            lsCommand,
+	"scan":          scanCommand,
+	"sbom":          sbomCommand,
+	"serve":         serveCommand,
+	"selftest":      selftestCommand,
+	"stub":          stubCommand,
+	"top":           topCommand,
+	"tree":          treeCommand,
+	"verify":        verifyCommand,
+	"verify-tree":   verifyTreeCommand,
+}
 
-	file, err := os.Open(*pharFilePath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Cannot open file: %s\n", err)
-		os.Exit(1)
-		return
+func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				fail(err)
+			}
+			return
+		}
 	}
 
-	stat, _ := file.Stat()
-	pharInfo, err := phargo.NewReader(file, stat.Size())
+	flag.Parse()
+
+	pharInfo, file, err := openPhar(*pharFilePath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Cannot parse file: %s\n", err)
-		os.Exit(1)
+		fail(err)
 		return
 	}
+	defer file.Close()
 
 	if *extractPath == "" {
 		d, _ := json.MarshalIndent(pharInfo, "", "  ")
 		fmt.Fprintf(os.Stdout, "%s\n", d)
 		return
 	}
-	
-	for _, file := range pharInfo.Files {
-		pathSave := filepath.Join(*extractPath, file.Filename)
-		f, err := file.Open()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Cannot extract %s file: %s\n", file.Filename, err)
-			os.Exit(1)
-			return
+
+	if err := extractDir(*pharFilePath, pharInfo, *extractPath); err != nil {
+		fail(err)
+	}
+}
+
+// newFlagSet create subcommand flags with global flags registered
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.StringVar(errorFormat, "error-format", "text", "Error output format: text or json")
+	return flags
+}
+
+// parseArgs parse flags allowing flags after positional arguments, returning positional arguments
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
 		}
-		defer f.Close()
-		
-		if baseDir := filepath.Dir(pathSave); baseDir != "." {
-			if _, err := os.Stat(baseDir); err != nil {
-				os.MkdirAll(baseDir, 0755)
-			}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		} else if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional, args = append(positional, args[0]), args[1:]
+	}
+}
+
+// openPhar parse phar file, http(s) URL or split archive from first part "app.phar.001",
+// returning closer of opened files
+func openPhar(filePath string) (*phargo.Phar, io.Closer, error) {
+	return openPharWithOptions(filePath, phargo.ReaderOptions{})
+}
+
+// openPharWithOptions is [openPhar] with reader options
+func openPharWithOptions(filePath string, options phargo.ReaderOptions) (*phargo.Phar, io.Closer, error) {
+	r, size, closer, err := openReader(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pharInfo, err := phargo.NewReaderWithOptions(r, size, options)
+	if err != nil {
+		closer.Close()
+		err := &cliError{Code: errorCode(err), Message: fmt.Sprintf("cannot parse file: %s", err), File: filePath, Err: err}
+		if err.Code == exitError {
+			err.Code = exitFormat
+		}
+		var crcErr *phargo.CRCError
+		if errors.As(err.Err, &crcErr) {
+			err.Entry = crcErr.Filename
 		}
-		
-		w, err := os.Create(pathSave)
+		return nil, nil, err
+	}
+	return pharInfo, closer, nil
+}
+
+// openReader open file, http(s) URL or split archive parts as one [io.ReaderAt]
+func openReader(filePath string) (io.ReaderAt, int64, io.Closer, error) {
+	if strings.HasPrefix(filePath, "http://") || strings.HasPrefix(filePath, "https://") {
+		remote, err := httpreaderat.New(filePath)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Cannot create %s file: %s\n", pathSave, err)
-			os.Exit(1)
-			return
+			return nil, 0, nil, &cliError{Code: exitIO, Message: fmt.Sprintf("cannot open url: %s", err), File: filePath, Err: err}
 		}
-		if _, err = io.Copy(w, f); err != nil {
-			fmt.Fprintf(os.Stderr, "Cannot write to %s: %s\n", pathSave, err)
-			os.Exit(1)
-			return
+		return remote, remote.Size(), io.NopCloser(nil), nil
+	} else if base, ok := strings.CutSuffix(filePath, ".001"); ok {
+		// First part of split archive, open all parts as one file
+		parts, err := multireaderat.OpenSplit(base)
+		if err != nil {
+			return nil, 0, nil, fileError(filePath, fmt.Errorf("cannot open split parts: %w", err))
 		}
-		f.Close()
-		
-		println(pathSave)
+		return parts, parts.Size(), parts, nil
 	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, 0, nil, fileError(filePath, fmt.Errorf("cannot open file: %w", err))
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, nil, fileError(filePath, fmt.Errorf("cannot get file stats: %w", err))
+	}
+	return file, stat.Size(), file, nil
+}
+
+// usageError return error with command usage
+func usageError(flags *flag.FlagSet, usage string) error {
+	message := fmt.Sprintf("usage: phargo %s %s", flags.Name(), strings.TrimSpace(usage))
+	return &cliError{Code: exitUsage, Message: message}
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+func exportPubkeyCommand(args []string) error {
+	flags := newFlagSet("export-pubkey")
+	keyPath := flags.String("key", "", "Public key file, default archive path with .pubkey suffix, must match archive signature")
+	output := flags.String("o", "-", "Write PEM public key to file, - to stdout")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 1 {
+		return usageError(flags, "[--key app.phar.pubkey] [-o key.pem] file.phar|URL")
+	} else if *keyPath == "" && strings.Contains(args[0], "://") {
+		return usageError(flags, "--key is required to remote archives")
+	}
+	if *keyPath == "" {
+		*keyPath = phargo.PublicKeyPath(args[0])
+	}
+
+	pharInfo, file, err := openPharWithOptions(args[0], phargo.ReaderOptions{Trusted: true})
+	if err != nil {
+		return err
+	}
+	file.Close()
+	if pharInfo.Signature == nil || pharInfo.Signature.Signature&phargo.SignatureOpenSSL == 0 {
+		return &cliError{Code: exitSignature, Message: "archive is not OpenSSL signed", File: args[0]}
+	}
+
+	data, err := os.ReadFile(*keyPath)
+	if err != nil {
+		return fileError(args[0], fmt.Errorf("cannot read public key: %w", err))
+	}
+	key, err := phargo.ParsePublicKey(data)
+	if err != nil {
+		return &cliError{Code: exitSignature, Message: fmt.Sprintf("%s: %s", *keyPath, err), File: args[0], Err: err}
+	}
+	if err := verifyOpenSSL(args[0], key); err != nil {
+		return err
+	}
+	encoded, err := phargo.MarshalPublicKey(key)
+	if err != nil {
+		return fileError(args[0], err)
+	}
+	fingerprint, err := phargo.PublicKeyFingerprint(key)
+	if err != nil {
+		return fileError(args[0], err)
+	}
+
+	// Fingerprint not mixed with PEM written to stdout
+	var info io.Writer = os.Stdout
+	if *output == "-" {
+		info = os.Stderr
+		os.Stdout.Write(encoded)
+	} else if err := os.WriteFile(*output, encoded, 0o644); err != nil {
+		return fileError(args[0], fmt.Errorf("cannot write public key: %w", err))
+	}
+	fmt.Fprintf(info, "%s: RSA %d bits, fingerprint SHA256:%x\n", *keyPath, key.N.BitLen(), fingerprint)
+	return nil
+}
+
+// verifyOpenSSL check archive OpenSSL signature with public key
+func verifyOpenSSL(pharPath string, key crypto.PublicKey) error {
+	r, size, closer, err := openReader(pharPath)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	return fileError(pharPath, phargo.VerifyOpenSSLSignature(r, size, key))
+}
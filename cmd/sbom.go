@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+func sbomCommand(args []string) error {
+	flags := newFlagSet("sbom")
+	name := flags.String("name", "", "Main component name, default to file name")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 1 {
+		return usageError(flags, "[--name app] file.phar")
+	}
+
+	pharInfo, file, err := openPhar(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if *name == "" {
+		*name = filepath.Base(args[0])
+	}
+	bom, err := pharInfo.CycloneDX(*name)
+	if err != nil {
+		return fileError(args[0], err)
+	}
+
+	js := json.NewEncoder(os.Stdout)
+	js.SetIndent("", "  ")
+	if err := js.Encode(bom); err != nil {
+		return fileError(args[0], err)
+	}
+	return nil
+}
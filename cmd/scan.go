@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+func scanCommand(args []string) error {
+	flags := newFlagSet("scan")
+	verify := flags.Bool("verify", false, "Check signature and CRC of found archives, reading them whole")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 1 {
+		return usageError(flags, "[--verify] dir/")
+	}
+
+	inventory, err := phargo.ScanDir(args[0], phargo.ReaderOptions{Trusted: !*verify})
+	if err != nil {
+		return fileError(args[0], err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(inventory); err != nil {
+		return fileError(args[0], err)
+	}
+	fmt.Fprintf(os.Stderr, "%d files scanned, %d phar found, %d skipped\n", inventory.Scanned, len(inventory.Phars), inventory.Skipped)
+	return nil
+}
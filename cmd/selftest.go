@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/fips140"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+// selftestContent is entry content of generated archives
+var selftestContent = []byte("phargo selftest content\n")
+
+// selftestBzip2 is selftestContent compressed with bzip2, Go std can only read bzip2
+var selftestBzip2 = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0xe9, 0xb9, 0x0f, 0x63, 0x00, 0x00,
+	0x08, 0x51, 0x80, 0x00, 0x10, 0x40, 0x00, 0x2b, 0xc5, 0xdc, 0x00, 0x20, 0x00, 0x31, 0x4c, 0x00,
+	0x01, 0x10, 0x68, 0x0f, 0x29, 0xfa, 0xa1, 0x0b, 0x92, 0x4c, 0x20, 0x51, 0xbf, 0x34, 0xd6, 0x47,
+	0x53, 0xe2, 0xee, 0x48, 0xa7, 0x0a, 0x12, 0x1d, 0x37, 0x21, 0xec, 0x60,
+}
+
+func selftestCommand(args []string) error {
+	flags := newFlagSet("selftest")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 0 {
+		return usageError(flags, "")
+	}
+
+	deflated := new(bytes.Buffer)
+	fw, _ := flate.NewWriter(deflated, flate.BestCompression)
+	fw.Write(selftestContent)
+	fw.Close()
+
+	compressions := []struct {
+		name  string
+		flags uint32
+		data  []byte
+	}{
+		{"none", phargo.EntryCompressedNone, selftestContent},
+		{"gzip", phargo.EntryCompressedGzip, deflated.Bytes()},
+		{"bzip2", phargo.EntryCompressedBzip2, selftestBzip2},
+	}
+	signatures := []phargo.SignatureFlag{
+		0, phargo.SignatureMD5, phargo.SignatureSHA1, phargo.SignatureSHA256, phargo.SignatureSHA512,
+		phargo.SignatureOpenSSL, phargo.SignatureOpenSSLSha256, phargo.SignatureOpenSSLSha512,
+	}
+
+	// OpenSSL archives are signed with in memory key, and checked with its public key
+	key, keyErr := rsa.GenerateKey(rand.Reader, 2048)
+
+	failed := 0
+	compressionOk, signatureOk := map[string]bool{}, map[phargo.SignatureFlag]bool{}
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "COMPRESSION\tSIGNATURE\tSTATUS")
+	for _, compression := range compressions {
+		for _, signature := range signatures {
+			name := "unsigned"
+			if signature != 0 {
+				name = signature.String()
+			}
+
+			status, err := "ok", keyErr
+			if signature&phargo.SignatureOpenSSL == 0 || keyErr == nil {
+				err = selftestArchive(compression.flags, compression.data, signature, key)
+			}
+			if err != nil {
+				status, failed = err.Error(), failed+1
+			}
+			if signature == 0 {
+				compressionOk[compression.name] = err == nil
+			}
+			if compression.flags == phargo.EntryCompressedNone {
+				signatureOk[signature] = err == nil
+			}
+			fmt.Fprintf(table, "%s\t%s\t%s\n", compression.name, name, status)
+		}
+	}
+	table.Flush()
+
+	// Capabilities are from archives results, compressions from unsigned archives and signatures from uncompressed archives
+	yesNo := map[bool]string{true: "yes", false: "no"}
+	fmt.Fprintln(os.Stdout, "\ncapabilities:")
+	for _, compression := range compressions[1:] {
+		fmt.Fprintf(os.Stdout, "  %s read: %s\n", compression.name, yesNo[compressionOk[compression.name]])
+	}
+	for _, signature := range signatures[1:] {
+		fmt.Fprintf(os.Stdout, "  %s verify: %s\n", signature, yesNo[signatureOk[signature]])
+	}
+	fmt.Fprintf(os.Stdout, "  fips 140-3 mode: %s\n", yesNo[fips140.Enabled()])
+
+	if failed > 0 {
+		return &cliError{Code: exitError, Message: fmt.Sprintf("%d of %d selftest archives failed", failed, len(compressions)*len(signatures))}
+	}
+	return nil
+}
+
+// selftestArchive build archive with [selftestPhar], parse it with signature and CRC checks
+// and compare entry content, OpenSSL signatures are checked with key public key.
+// Hashes disabled by strict FIPS 140-3 mode panic and are reported as error.
+func selftestArchive(compression uint32, stored []byte, signature phargo.SignatureFlag, key *rsa.PrivateKey) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	data, err := selftestPhar(compression, stored, signature, key)
+	if err != nil {
+		return err
+	}
+	pharInfo, err := phargo.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	} else if signature&phargo.SignatureOpenSSL != 0 {
+		if err := phargo.VerifyOpenSSLSignature(bytes.NewReader(data), int64(len(data)), &key.PublicKey); err != nil {
+			return err
+		}
+	}
+	f, err := pharInfo.Files[0].Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if content, err := io.ReadAll(f); err != nil {
+		return err
+	} else if !bytes.Equal(content, selftestContent) {
+		return fmt.Errorf("content mismatch")
+	}
+	return nil
+}
+
+// selftestPhar build archive with one entry of selftestContent stored as data
+// with compression flags, signed with signature if not zero, OpenSSL signatures are made with key
+func selftestPhar(compression uint32, data []byte, signature phargo.SignatureFlag, key *rsa.PrivateKey) ([]byte, error) {
+	le := binary.LittleEndian
+	globalFlags := compression
+	if signature != 0 {
+		globalFlags |= uint32(phargo.GlobalSigned)
+	}
+
+	name := "selftest.txt"
+	manifest := le.AppendUint32(nil, 1)     // Entries count
+	manifest = append(manifest, 0x11, 0x00) // API version 1.1.0
+	manifest = le.AppendUint32(manifest, globalFlags)
+	manifest = le.AppendUint32(manifest, 0) // Alias length
+	manifest = le.AppendUint32(manifest, 0) // Metadata length
+	manifest = le.AppendUint32(manifest, uint32(len(name)))
+	manifest = append(manifest, name...)
+	manifest = le.AppendUint32(manifest, uint32(len(selftestContent)))
+	manifest = le.AppendUint32(manifest, 0) // Timestamp
+	manifest = le.AppendUint32(manifest, uint32(len(data)))
+	manifest = le.AppendUint32(manifest, crc32.ChecksumIEEE(selftestContent))
+	manifest = le.AppendUint32(manifest, phargo.EntryPermDefFile|compression)
+	manifest = le.AppendUint32(manifest, 0) // Entry metadata length
+
+	phar := []byte("<?php __HALT_COMPILER(); ?>\r\n")
+	phar = le.AppendUint32(phar, uint32(len(manifest)))
+	phar = append(append(phar, manifest...), data...)
+	if signature == 0 {
+		return phar, nil
+	} else if signature&phargo.SignatureOpenSSL != 0 {
+		hashType := map[phargo.SignatureFlag]crypto.Hash{
+			phargo.SignatureOpenSSL:       crypto.SHA1,
+			phargo.SignatureOpenSSLSha256: crypto.SHA256,
+			phargo.SignatureOpenSSLSha512: crypto.SHA512,
+		}[signature]
+		digest := hashType.New()
+		digest.Write(phar)
+		sign, err := rsa.SignPKCS1v15(rand.Reader, key, hashType, digest.Sum(nil))
+		if err != nil {
+			return nil, err
+		}
+		phar = append(phar, sign...)
+		phar = le.AppendUint32(phar, uint32(len(sign)))
+		phar = le.AppendUint32(phar, uint32(signature))
+		return append(phar, "GBMB"...), nil
+	}
+
+	hash := map[phargo.SignatureFlag]func([]byte) []byte{
+		phargo.SignatureMD5:    func(b []byte) []byte { sum := md5.Sum(b); return sum[:] },
+		phargo.SignatureSHA1:   func(b []byte) []byte { sum := sha1.Sum(b); return sum[:] },
+		phargo.SignatureSHA256: func(b []byte) []byte { sum := sha256.Sum256(b); return sum[:] },
+		phargo.SignatureSHA512: func(b []byte) []byte { sum := sha512.Sum512(b); return sum[:] },
+	}[signature](phar)
+	phar = append(phar, hash...)
+	phar = le.AppendUint32(phar, uint32(signature))
+	return append(phar, "GBMB"...), nil
+}
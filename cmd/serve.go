@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+)
+
+func serveCommand(args []string) error {
+	flags := newFlagSet("serve")
+	addr := flags.String("addr", ":8080", "Address to listen HTTP server")
+	api := flags.Bool("api", false, "Serve json api with /manifest, /files, /files/{path} and /verify")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 1 {
+		return usageError(flags, "file.phar [--addr :8080] [--api]")
+	}
+
+	pharInfo, file, err := openPhar(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	handler := http.FileServerFS(pharInfo)
+	if *api {
+		handler = apiHandler(args[0], pharInfo)
+	}
+	fmt.Fprintf(os.Stderr, "Serving %s on %s\n", args[0], *addr)
+	return http.ListenAndServe(*addr, handler)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Sirherobrine23/phargo"
+	"github.com/Sirherobrine23/phargo/stubs"
+)
+
+func stubCommand(args []string) error {
+	flags := newFlagSet("stub")
+	alias := flags.String("alias", "", "Archive alias, default app.phar")
+	entry := flags.String("entry", "", "Entry run by stub, cli and selfextract stubs")
+	minPHP := flags.String("min-php", "", "Minimum PHP version, cli stub")
+	index := flags.String("index", "", "Index entry, web stub, default index.php")
+	notFound := flags.String("not-found", "", "Entry served to missing files, web stub")
+	mimeFrom := flags.String("mime-from", "", "Add MIME map of entries extensions from archive or folder, web stub")
+	dir := flags.String("dir", "", "Folder to extract files, selfextract stub, default temporary folder")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	var stub []byte
+	switch {
+	case len(args) != 1:
+		return usageError(flags, "cli|web|selfextract [--alias app.phar] [--entry bin/app.php] [--min-php 8.1.0] [--index index.php] [--not-found 404.php] [--mime-from app.phar|dir] [--dir path]")
+	case args[0] == "cli":
+		stub, err = stubs.CLI(stubs.CLIOptions{Alias: *alias, Entry: *entry, MinPHPVersion: *minPHP})
+	case args[0] == "web":
+		options := stubs.WebOptions{Alias: *alias, Index: *index, NotFound: *notFound}
+		if *mimeFrom != "" {
+			names, namesErr := entryNames(*mimeFrom)
+			if namesErr != nil {
+				return namesErr
+			}
+			options.MIMETypes = stubs.MIMETypes(names)
+		}
+		stub, err = stubs.Web(options)
+	case args[0] == "selfextract":
+		stub, err = stubs.SelfExtract(stubs.SelfExtractOptions{Dir: *dir, Entry: *entry})
+	default:
+		return usageError(flags, "stub kind must be cli, web or selfextract")
+	}
+	if err != nil {
+		return &cliError{Code: exitUsage, Message: err.Error(), Err: err}
+	}
+	_, err = os.Stdout.Write(stub)
+	return err
+}
+
+// entryNames return entries names of phar file, or files names inside folder
+func entryNames(source string) ([]string, error) {
+	if stat, err := os.Stat(source); err == nil && stat.IsDir() {
+		var names []string
+		err := filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				names = append(names, path)
+			}
+			return err
+		})
+		return names, fileError(source, err)
+	}
+
+	pharInfo, file, err := openPharWithOptions(source, phargo.ReaderOptions{Trusted: true})
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	names := make([]string, len(pharInfo.Files))
+	for index, entry := range pharInfo.Files {
+		names[index] = entry.Filename
+	}
+	return names, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+func topCommand(args []string) error {
+	flags := newFlagSet("top")
+	count := flags.Int("n", 10, "Number of entries listed")
+	stored := flags.Bool("stored", false, "Sort by compressed size stored in archive")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 1 || *count < 1 {
+		return usageError(flags, "[-n 10] [--stored] file.phar|URL")
+	}
+
+	pharInfo, file, err := openPharWithOptions(args[0], phargo.ReaderOptions{Trusted: true})
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var compressed, uncompressed int64
+	for _, entry := range pharInfo.Files {
+		compressed += entry.SizeCompressed
+		uncompressed += entry.SizeUncompressed
+	}
+
+	files := pharInfo.SortedFiles(phargo.SortBySize)
+	if *stored {
+		slices.SortStableFunc(files, func(a, b *phargo.File) int { return cmp.Compare(b.SizeCompressed, a.SizeCompressed) })
+	}
+	files = slices.DeleteFunc(files, func(entry *phargo.File) bool { return entry.FileInfo().IsDir() })
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "SIZE\tSHARE\tSTORED\tSHARE\tRATIO\t NAME")
+	for _, entry := range files[:min(*count, len(files))] {
+		fmt.Fprintf(table, "%d\t%s\t%d\t%s\t%s\t %s\n", entry.SizeUncompressed, percent(entry.SizeUncompressed, uncompressed),
+			entry.SizeCompressed, percent(entry.SizeCompressed, compressed), percent(entry.SizeCompressed, entry.SizeUncompressed), entry.Filename)
+	}
+	table.Flush()
+	fmt.Fprintf(os.Stdout, "\n%d bytes, %d bytes stored in %d entries\n", uncompressed, compressed, len(pharInfo.Files))
+	return nil
+}
+
+// percent format part of total, "-" if total is zero
+func percent(part, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
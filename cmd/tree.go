@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+// treeNode is entry or folder of archive tree, folders size is sum of all files inside
+type treeNode struct {
+	dir      bool
+	size     int64
+	files    int
+	children map[string]*treeNode
+}
+
+func treeCommand(args []string) error {
+	flags := newFlagSet("tree")
+	depth := flags.Int("depth", 0, "Max folders depth printed, 0 to all")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 1 || *depth < 0 {
+		return usageError(flags, "[--depth N] file.phar|URL")
+	}
+
+	pharInfo, file, err := openPharWithOptions(args[0], phargo.ReaderOptions{Trusted: true})
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	root := buildTree(pharInfo.Files)
+	width := len(fmt.Sprint(root.size))
+	fmt.Fprintf(os.Stdout, "[%*d]  %s\n", width, root.size, args[0])
+	printTree(os.Stdout, root, "", width, *depth)
+	fmt.Fprintf(os.Stdout, "\n%d bytes in %d files\n", root.size, root.files)
+	return nil
+}
+
+// buildTree group entries by folder, adding folders not stored in archive
+func buildTree(files []*phargo.File) *treeNode {
+	root := &treeNode{dir: true, children: map[string]*treeNode{}}
+	for _, file := range files {
+		name := strings.Trim(file.Filename, "/")
+		if name == "" {
+			continue
+		}
+
+		node, isDir := root, file.FileInfo().IsDir()
+		for index, part := range strings.Split(name, "/") {
+			if !isDir {
+				node.size += file.SizeUncompressed
+				node.files++
+			}
+			child, ok := node.children[part]
+			if !ok {
+				child = &treeNode{}
+				node.children[part] = child
+			}
+			if (index < strings.Count(name, "/") || isDir) && !child.dir {
+				child.dir, child.children = true, map[string]*treeNode{}
+			}
+			node = child
+		}
+		if !isDir {
+			node.size += file.SizeUncompressed
+			node.files++
+		}
+	}
+	return root
+}
+
+// printTree print node children sorted by name, folders end with "/" and show size of all files inside
+func printTree(w io.Writer, node *treeNode, prefix string, width, depth int) {
+	for index, name := range slices.Sorted(maps.Keys(node.children)) {
+		child := node.children[name]
+		branch, next := "├── ", "│   "
+		if index == len(node.children)-1 {
+			branch, next = "└── ", "    "
+		}
+		if child.dir {
+			name += "/"
+		}
+		fmt.Fprintf(w, "%s%s[%*d]  %s\n", prefix, branch, width, child.size, name)
+		if child.dir && depth != 1 {
+			printTree(w, child, prefix+next, width, max(depth-1, 0))
+		}
+	}
+}
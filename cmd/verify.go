@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+func verifyCommand(args []string) error {
+	flags := newFlagSet("verify")
+	crcOnly := flags.Bool("crc-only", false, "Check only entries CRC, not hashing archive")
+	sigOnly := flags.Bool("sig-only", false, "Check only signature, not decompressing entries")
+	keyPath := flags.String("key", "", "Public key to OpenSSL signatures, default archive path with .pubkey suffix")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 1 || (*crcOnly && *sigOnly) {
+		return usageError(flags, "[--crc-only | --sig-only] [--key app.phar.pubkey] file.phar|URL")
+	}
+
+	scope := phargo.VerifyScopeAll
+	switch {
+	case *crcOnly:
+		scope = phargo.VerifyScopeCRC
+	case *sigOnly:
+		scope = phargo.VerifyScopeSignature
+	}
+
+	// Parse without checks, Verify run only selected ones
+	pharInfo, file, err := openPharWithOptions(args[0], phargo.ReaderOptions{Trusted: true})
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// OpenSSL signature is checked with public key, Verify check only CRC
+	openssl := scope&phargo.VerifyScopeSignature != 0 && pharInfo.Signature != nil && pharInfo.Signature.Signature&phargo.SignatureOpenSSL != 0
+	if openssl {
+		if *keyPath == "" {
+			*keyPath = phargo.PublicKeyPath(args[0])
+		}
+		data, err := os.ReadFile(*keyPath)
+		if err != nil {
+			return fileError(args[0], fmt.Errorf("cannot read public key to OpenSSL signature: %w", err))
+		}
+		key, err := phargo.ParsePublicKey(data)
+		if err != nil {
+			return &cliError{Code: exitSignature, Message: fmt.Sprintf("%s: %s", *keyPath, err), File: args[0], Err: err}
+		} else if err := verifyOpenSSL(args[0], key); err != nil {
+			return err
+		}
+		scope &^= phargo.VerifyScopeSignature
+	}
+
+	if err := pharInfo.Verify(scope); err != nil {
+		var crcErr *phargo.CRCError
+		if errors.As(err, &crcErr) {
+			return entryError(args[0], crcErr.Filename, err)
+		}
+		return fileError(args[0], err)
+	}
+
+	if openssl {
+		fmt.Fprintf(os.Stdout, "signature: %s ok with %s\n", pharInfo.Signature.Signature, *keyPath)
+	} else if scope&phargo.VerifyScopeSignature != 0 {
+		if pharInfo.Signature == nil {
+			fmt.Fprintln(os.Stdout, "signature: unsigned")
+		} else {
+			fmt.Fprintf(os.Stdout, "signature: %s ok\n", pharInfo.Signature.Signature)
+		}
+	}
+	if scope&phargo.VerifyScopeCRC != 0 {
+		fmt.Fprintf(os.Stdout, "crc: %d entries ok\n", len(pharInfo.Files))
+	}
+	return nil
+}
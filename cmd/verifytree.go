@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+func verifyTreeCommand(args []string) error {
+	flags := newFlagSet("verify-tree")
+	allowExtra := flags.Bool("allow-extra", false, "Ignore files in directory not present in archive")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	} else if len(args) != 2 {
+		return usageError(flags, "[--allow-extra] file.phar ./extracted")
+	}
+
+	pharInfo, file, err := openPhar(args[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	root := args[1]
+	inArchive := map[string]bool{}
+	differences := 0
+	for _, entry := range pharInfo.Files {
+		if !filepath.IsLocal(filepath.FromSlash(entry.Filename)) {
+			// Not stat files outside root
+			return entryError(args[0], entry.Filename, fmt.Errorf("%w: %q", phargo.ErrUnsafePath, entry.Filename))
+		} else if entry.FileInfo().IsDir() {
+			continue
+		}
+		inArchive[entry.Filename] = true
+
+		diskPath := filepath.Join(root, filepath.FromSlash(entry.Filename))
+		stat, err := os.Stat(diskPath)
+		switch {
+		case err != nil:
+			differences++
+			fmt.Fprintf(os.Stdout, "missing\t%s\n", entry.Filename)
+			continue
+		case stat.IsDir():
+			differences++
+			fmt.Fprintf(os.Stdout, "not file\t%s\n", entry.Filename)
+			continue
+		case stat.Size() != entry.SizeUncompressed:
+			differences++
+			fmt.Fprintf(os.Stdout, "size\t%s\texpect %d, found %d\n", entry.Filename, entry.SizeUncompressed, stat.Size())
+			continue
+		}
+
+		sum, err := fileCRC(diskPath)
+		if err != nil {
+			return entryError(args[0], entry.Filename, err)
+		} else if sum != entry.CRC {
+			differences++
+			fmt.Fprintf(os.Stdout, "checksum\t%s\texpect %08x, found %08x\n", entry.Filename, entry.CRC, sum)
+		}
+	}
+
+	if !*allowExtra {
+		var extra []string
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			name, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			if name = filepath.ToSlash(name); !inArchive[name] {
+				extra = append(extra, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return fileError(args[0], err)
+		}
+
+		slices.Sort(extra)
+		for _, name := range extra {
+			differences++
+			fmt.Fprintf(os.Stdout, "extra\t%s\n", name)
+		}
+	}
+
+	if differences > 0 {
+		return &cliError{Code: exitMismatch, Message: fmt.Sprintf("%s differs from %s in %d files", root, args[0], differences), File: args[0]}
+	}
+	fmt.Fprintf(os.Stdout, "%s matches %s (%d files)\n", root, args[0], len(inArchive))
+	return nil
+}
+
+// fileCRC compute file crc32 as stored in phar manifest
+func fileCRC(path string) (uint32, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	hash := crc32.NewIEEE()
+	if _, err := phargo.CopyBuffer(hash, file); err != nil {
+		return 0, err
+	}
+	return hash.Sum32(), nil
+}
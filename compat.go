@@ -0,0 +1,108 @@
+package phargo
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CompatRequirement is PHP version or extension needed to load archive
+type CompatRequirement struct {
+	PHPVersion string // Minimum PHP version, empty if only extension is required
+	Extension  string // PHP extension, empty if only version is required
+	Reason     string
+}
+
+func (req CompatRequirement) String() string {
+	switch {
+	case req.PHPVersion != "" && req.Extension != "":
+		return fmt.Sprintf("requires PHP >= %s with %s extension: %s", req.PHPVersion, req.Extension, req.Reason)
+	case req.Extension != "":
+		return fmt.Sprintf("requires %s extension: %s", req.Extension, req.Reason)
+	}
+	return fmt.Sprintf("requires PHP >= %s: %s", req.PHPVersion, req.Reason)
+}
+
+// CompatibilityReport is PHP runtime needed to load archive, from manifest API
+// version, signature algorithm, entries compression and stub version checks
+type CompatibilityReport struct {
+	MinPHPVersion string   // Highest version of requirements
+	Extensions    []string // Sorted extensions of requirements
+	Requirements  []CompatRequirement
+}
+
+// Compatibility report PHP version and extensions needed to load archive
+func Compatibility(p *Phar) CompatibilityReport {
+	var report CompatibilityReport
+	add := func(version, extension, reason string) {
+		report.Requirements = append(report.Requirements, CompatRequirement{PHPVersion: version, Extension: extension, Reason: reason})
+		if version != "" && compareVersion(version, report.MinPHPVersion) > 0 {
+			report.MinPHPVersion = version
+		}
+		if extension != "" && !slices.Contains(report.Extensions, extension) {
+			report.Extensions = append(report.Extensions, extension)
+		}
+	}
+
+	add("", "phar", "archive format")
+	if p.Manifest != nil {
+		switch version := p.Manifest.Version; {
+		case compareVersion(version, "1.1.0") >= 0:
+			add("5.3.0", "", fmt.Sprintf("manifest API %s is from bundled phar extension", version))
+		case compareVersion(version, "1.0.0") >= 0:
+			add("5.2.0", "", fmt.Sprintf("manifest API %s", version))
+		}
+	}
+
+	if p.Signature != nil {
+		switch sig := p.Signature.Signature; sig {
+		case SignatureSHA256, SignatureSHA512:
+			add("5.3.0", "hash", fmt.Sprintf("verify %s signature", sig))
+		case SignatureOpenSSL:
+			add("5.3.0", "openssl", fmt.Sprintf("verify %s signature", sig))
+		case SignatureOpenSSLSha256, SignatureOpenSSLSha512:
+			add("8.1.0", "openssl", fmt.Sprintf("verify %s signature", sig))
+		}
+	}
+
+	var gzip, bzip2 bool
+	for _, file := range p.Files {
+		gzip = gzip || file.Flags.Compression() == EntryCompressedGzip
+		bzip2 = bzip2 || file.Flags.Compression() == EntryCompressedBzip2
+	}
+	if gzip {
+		add("", "zlib", "read gzip compressed entries")
+	}
+	if bzip2 {
+		add("", "bz2", "read bzip2 compressed entries")
+	}
+
+	if p.Stub.PHPVersion != "" {
+		add(p.Stub.PHPVersion, "", "stub version check")
+	}
+	slices.Sort(report.Extensions)
+	return report
+}
+
+// compareVersion compare dotted PHP versions numerically, empty version is lowest
+func compareVersion(a, b string) int {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	if a == "" || b == "" {
+		return strings.Compare(a, b)
+	}
+	for index := range max(len(partsA), len(partsB)) {
+		var numberA, numberB int
+		if index < len(partsA) {
+			numberA, _ = strconv.Atoi(partsA[index])
+		}
+		if index < len(partsB) {
+			numberB, _ = strconv.Atoi(partsB[index])
+		}
+		if numberA != numberB {
+			return cmp.Compare(numberA, numberB)
+		}
+	}
+	return 0
+}
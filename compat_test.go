@@ -0,0 +1,34 @@
+package phargo
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestCompatibility(t *testing.T) {
+	data := buildPhar(testEntry{name: "index.php", data: []byte("<?php"), flags: EntryPermDefFile | EntryCompressedBzip2})
+	file, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{SkipCRC: true})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	file.Signature = &Signature{Signature: SignatureOpenSSLSha256}
+	file.Stub.PHPVersion = "7.2.0"
+
+	report := Compatibility(file)
+	if report.MinPHPVersion != "8.1.0" {
+		t.Errorf("Wrong minimum version: %q", report.MinPHPVersion)
+		return
+	} else if !slices.Equal(report.Extensions, []string{"bz2", "openssl", "phar"}) {
+		t.Errorf("Wrong extensions: %q", report.Extensions)
+		return
+	}
+
+	file.Signature = nil
+	file.Stub.PHPVersion = "7.10"
+	if report := Compatibility(file); report.MinPHPVersion != "7.10" {
+		t.Errorf("Should compare versions numerically, got %q", report.MinPHPVersion)
+		return
+	}
+}
@@ -0,0 +1,106 @@
+package phargo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ErrNoComposer is returned when archive has no composer.lock or vendor/composer/installed.json
+var ErrNoComposer = errors.New("archive has no composer data")
+
+// ComposerPackage is PHP package bundled in archive
+type ComposerPackage struct {
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	Type      string   `json:"type,omitempty"`
+	License   []string `json:"license,omitempty"`
+	Source    string   `json:"source,omitempty"`    // Repository url
+	Reference string   `json:"reference,omitempty"` // Commit or tag of source
+	Dev       bool     `json:"dev,omitempty"`       // From packages-dev or dev-package-names
+}
+
+// composerLock is composer.lock and Composer 2 installed.json layout
+type composerLock struct {
+	Packages        []composerPackage `json:"packages"`
+	PackagesDev     []composerPackage `json:"packages-dev"`
+	DevPackageNames []string          `json:"dev-package-names"`
+}
+
+type composerPackage struct {
+	Name    string   `json:"name"`
+	Version string   `json:"version"`
+	Type    string   `json:"type"`
+	License []string `json:"license"`
+	Source  struct {
+		URL       string `json:"url"`
+		Reference string `json:"reference"`
+	} `json:"source"`
+}
+
+// ComposerFile return name of composer.lock closest to archive root, or
+// vendor/composer/installed.json if archive has no lock file
+func (phar *Phar) ComposerFile() (string, error) {
+	var lock, installed string
+	for _, file := range phar.Files {
+		switch name := file.Filename; {
+		case path.Base(name) == "composer.lock" && (lock == "" || strings.Count(name, "/") < strings.Count(lock, "/")):
+			lock = name
+		case (name == "vendor/composer/installed.json" || strings.HasSuffix(name, "/vendor/composer/installed.json")) &&
+			(installed == "" || len(name) < len(installed)):
+			installed = name
+		}
+	}
+
+	switch {
+	case lock != "":
+		return lock, nil
+	case installed != "":
+		return installed, nil
+	}
+	return "", ErrNoComposer
+}
+
+// ComposerPackages return packages bundled in archive from [Phar.ComposerFile]
+func (phar *Phar) ComposerPackages() ([]ComposerPackage, error) {
+	name, err := phar.ComposerFile()
+	if err != nil {
+		return nil, err
+	}
+	data, err := fs.ReadFile(phar, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var lock composerLock
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		// Composer 1 installed.json is packages array
+		err = json.Unmarshal(data, &lock.Packages)
+	} else {
+		err = json.Unmarshal(data, &lock)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", name, err)
+	}
+
+	dev := make(map[string]bool, len(lock.DevPackageNames))
+	for _, name := range lock.DevPackageNames {
+		dev[name] = true
+	}
+	packages := make([]ComposerPackage, 0, len(lock.Packages)+len(lock.PackagesDev))
+	for index, pkg := range append(lock.Packages, lock.PackagesDev...) {
+		packages = append(packages, ComposerPackage{
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Type:      pkg.Type,
+			License:   pkg.License,
+			Source:    pkg.Source.URL,
+			Reference: pkg.Source.Reference,
+			Dev:       index >= len(lock.Packages) || dev[pkg.Name],
+		})
+	}
+	return packages, nil
+}
@@ -0,0 +1,77 @@
+package phargo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+const testComposerLock = `{
+	"packages": [
+		{"name": "symfony/console", "version": "v6.4.1", "type": "library", "license": ["MIT"], "source": {"type": "git", "url": "https://github.com/symfony/console.git", "reference": "a550a7c"}}
+	],
+	"packages-dev": [
+		{"name": "phpunit/phpunit", "version": "10.5.2", "license": ["BSD-3-Clause"]}
+	]
+}`
+
+func TestComposerPackages(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "vendor/composer/installed.json", data: []byte(`[{"name": "old/package", "version": "1.0.0"}]`)},
+		testEntry{name: "composer.lock", data: []byte(testComposerLock)},
+	)
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	packages, err := file.ComposerPackages()
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(packages) != 2 {
+		t.Errorf("Should get 2 packages, got %+v", packages)
+		return
+	} else if pkg := packages[0]; pkg.Name != "symfony/console" || pkg.Version != "v6.4.1" || pkg.Reference != "a550a7c" || pkg.Dev {
+		t.Errorf("Wrong package: %+v", pkg)
+		return
+	} else if !packages[1].Dev {
+		t.Error("packages-dev should be dev")
+		return
+	}
+
+	bom, err := file.CycloneDX("app.phar")
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(bom.Components) != 2 {
+		t.Errorf("Should get 2 components, got %+v", bom.Components)
+		return
+	} else if component := bom.Components[0]; component.PURL != "pkg:composer/symfony/console@v6.4.1" || component.Group != "symfony" || component.Name != "console" || component.Licenses[0].License.ID != "MIT" {
+		t.Errorf("Wrong component: %+v", component)
+		return
+	} else if bom.Components[1].Scope != "excluded" {
+		t.Errorf("Dev component should be excluded, got %q", bom.Components[1].Scope)
+		return
+	}
+
+	// Composer 2 installed.json without lock file
+	data = buildPhar(testEntry{name: "vendor/composer/installed.json", data: []byte(`{"packages": [{"name": "a/b", "version": "2.0.0"}, {"name": "c/d", "version": "dev-main"}], "dev-package-names": ["c/d"]}`)})
+	if file, err = NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if packages, err = file.ComposerPackages(); err != nil || len(packages) != 2 || packages[0].Dev || !packages[1].Dev {
+		t.Errorf("Wrong installed.json packages: %+v, %v", packages, err)
+		return
+	}
+
+	data = buildPhar(testEntry{name: "index.php", data: []byte("<?php")})
+	if file, err = NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if _, err := file.CycloneDX("app.phar"); !errors.Is(err, ErrNoComposer) {
+		t.Errorf("Should get ErrNoComposer, got %v", err)
+		return
+	}
+}
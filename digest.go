@@ -0,0 +1,55 @@
+package phargo
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+)
+
+// ContentDigest return SHA-256 of archive manifest and entries content, without stub
+// and signature, so same content re-signed, re-stubbed or recompressed hash the same.
+//
+// Hash input is version, alias and metadata of manifest, then to each entry in manifest
+// order its name, timestamp, permissions, metadata and uncompressed content,
+// each field prefixed with its length.
+func (phar *Phar) ContentDigest() ([]byte, error) {
+	digest := sha256.New()
+	if phar.Manifest != nil {
+		writeDigestField(digest, []byte(phar.Manifest.Version))
+		writeDigestField(digest, phar.Manifest.Alias)
+		writeDigestField(digest, phar.Manifest.Metadata)
+	}
+
+	var fields [16]byte
+	for _, file := range phar.Files {
+		writeDigestField(digest, []byte(file.Filename))
+		binary.LittleEndian.PutUint64(fields[0:8], uint64(file.Timestamp.Unix()))
+		binary.LittleEndian.PutUint64(fields[8:16], uint64(file.Flags.Perm()))
+		digest.Write(fields[:])
+		writeDigestField(digest, file.MetaSerialized)
+
+		binary.LittleEndian.PutUint64(fields[0:8], uint64(file.SizeUncompressed))
+		digest.Write(fields[0:8])
+		f, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("cannot open %s to digest: %w", file.Filename, err)
+		}
+		n, err := copyBuffer(digest, f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cannot digest %s content: %w", file.Filename, err)
+		} else if n != file.SizeUncompressed {
+			return nil, fmt.Errorf("%w: %s has %d bytes, expect %d", ErrTruncated, file.Filename, n, file.SizeUncompressed)
+		}
+	}
+	return digest.Sum(nil), nil
+}
+
+// writeDigestField write data length and data to digest
+func writeDigestField(digest hash.Hash, data []byte) {
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(len(data)))
+	digest.Write(length[:])
+	digest.Write(data)
+}
@@ -0,0 +1,38 @@
+package phargo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestContentDigest(t *testing.T) {
+	entries := []testEntry{{name: "a.txt", data: []byte("AAAA")}, {name: "b.txt", data: []byte("BBBB")}}
+	unsigned := buildPhar(entries...)
+	restubbed := append([]byte("#!/usr/bin/env php\n<?php echo 'new stub'; "), unsigned[len("<?php "):]...)
+	signed := signPhar(buildPhar(entries...))
+	entries[1].data = []byte("CCCC")
+	changed := buildPhar(entries...)
+
+	var digests [][]byte
+	for _, data := range [][]byte{unsigned, restubbed, signed, changed} {
+		file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		}
+		sum, err := file.ContentDigest()
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		}
+		digests = append(digests, sum)
+	}
+
+	if !bytes.Equal(digests[0], digests[1]) {
+		t.Error("Stub changed digest")
+	} else if !bytes.Equal(digests[0], digests[2]) {
+		t.Error("Signature changed digest")
+	} else if bytes.Equal(digests[0], digests[3]) {
+		t.Error("Content change not changed digest")
+	}
+}
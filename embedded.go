@@ -0,0 +1,72 @@
+package phargo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrNoEmbedded is returned when [FindEmbedded] find no valid phar archive
+var ErrNoEmbedded = errors.New("no embedded phar found")
+
+// Bytes before __HALT_COMPILER searched for stub start, and stub starts tried, by [FindEmbedded]
+const (
+	embeddedStubSearch = 1 << 20
+	embeddedMaxStarts  = 16
+)
+
+// FindEmbedded locate phar appended to other file, like self-extracting launchers or
+// binaries with trailing phar, returning offset of phar start to parse it with
+// [io.NewSectionReader], zero to plain phar files.
+//
+// Each __HALT_COMPILER marker is tried with stub starting at each "<?php" before it, latest
+// first, or at "#!" in line before that. Signed archives are accepted only at offset where
+// signature match, unsigned ones at latest "<?php" as their stub can't be checked.
+func FindEmbedded(r io.ReaderAt, size int64) (int64, error) {
+	var base int64
+	for base < size {
+		offset, err := getOffset(io.NewSectionReader(r, base, size-base), stubScanChunkSize, haltCompiler)
+		if err != nil {
+			break
+		}
+		archive := base + offset
+		base = archive
+
+		for _, start := range embeddedStubStarts(r, archive) {
+			section := io.NewSectionReader(r, start, size-start)
+			if _, err := NewReaderWithOptions(section, size-start, ReaderOptions{SkipCRC: true}); err == nil {
+				return start, nil
+			}
+		}
+	}
+	return 0, ErrNoEmbedded
+}
+
+// embeddedStubStarts return offsets of "<?php" before archive start, latest first,
+// moved to "#!" in line before it if any
+func embeddedStubStarts(r io.ReaderAt, archive int64) []int64 {
+	windowStart := max(archive-embeddedStubSearch, 0)
+	window, err := readAt(r, windowStart, archive-windowStart)
+	if err != nil {
+		return nil
+	}
+
+	var starts []int64
+	for len(starts) < embeddedMaxStarts {
+		index := bytes.LastIndex(window, []byte("<?php"))
+		if index < 0 {
+			break
+		}
+		window = window[:index]
+
+		start := index
+		if index > 0 && window[index-1] == '\n' {
+			lineStart := bytes.LastIndexByte(window[:index-1], '\n') + 1
+			if shebang := bytes.LastIndex(window[lineStart:index-1], []byte("#!")); shebang >= 0 {
+				start = lineStart + shebang
+			}
+		}
+		starts = append(starts, windowStart+int64(start))
+	}
+	return starts
+}
@@ -0,0 +1,36 @@
+package phargo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestFindEmbedded(t *testing.T) {
+	launcher := []byte("\x7fELF\x00\x00<?php strings in binary __HALT_COMPILER and __HALT_COMPILER();\x00\xff")
+	plain := signPhar(buildPhar(testEntry{name: "a.txt", data: []byte("AAAA")}))
+	shebang := signPhar(append([]byte("#!/usr/bin/env php\n"), buildPhar(testEntry{name: "a.txt", data: []byte("AAAA")})...))
+
+	for name, test := range map[string]struct {
+		data   []byte
+		offset int64
+	}{
+		"plain":    {plain, 0},
+		"appended": {append(bytes.Clone(launcher), plain...), int64(len(launcher))},
+		"shebang":  {append(bytes.Clone(launcher), shebang...), int64(len(launcher))},
+	} {
+		offset, err := FindEmbedded(bytes.NewReader(test.data), int64(len(test.data)))
+		if err != nil {
+			t.Errorf("Got error with %s: %s", name, err)
+			return
+		} else if offset != test.offset {
+			t.Errorf("Wrong offset with %s: expect %d, got %d", name, test.offset, offset)
+			return
+		}
+	}
+
+	if _, err := FindEmbedded(bytes.NewReader(launcher), int64(len(launcher))); !errors.Is(err, ErrNoEmbedded) {
+		t.Errorf("Should get ErrNoEmbedded, got %v", err)
+		return
+	}
+}
@@ -0,0 +1,528 @@
+package phargo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrUnsafePath is returned when entry name is absolute or escape extraction folder
+var ErrUnsafePath = errors.New("unsafe entry path")
+
+// ExtractOption configure [Extract]
+type ExtractOption func(*extractConfig)
+
+type extractConfig struct {
+	onExtracted   func(file *File, path string)
+	windowsNames  WindowsNamePolicy
+	absolutePaths AbsolutePathPolicy
+	noSymlinks    bool
+	inspector     func(name string, r io.Reader) error
+	progress      ProgressFunc
+	filter        func(file *File) bool
+	overwrite     OverwritePolicy
+	preserveMode  bool
+	preserveTimes bool
+}
+
+// OverwritePolicy control files already in extraction folder
+type OverwritePolicy int
+
+const (
+	OverwriteReplace OverwritePolicy = iota // Truncate and write existing files
+	OverwriteSkip                           // Keep existing files, not writing entry
+	OverwriteReject                         // Stop extraction with [fs.ErrExist]
+)
+
+// WindowsNamePolicy control entry names not valid on Windows, with
+// illegal characters like "\" or ":", reserved device names like CON or NUL,
+// or components ending with dot or space
+type WindowsNamePolicy int
+
+const (
+	WindowsNamesAuto   WindowsNamePolicy = iota // Reject invalid names when extracting on Windows
+	WindowsNamesReject                          // Reject invalid names on any system
+	WindowsNamesMangle                          // Replace invalid characters and reserved names with safe names
+)
+
+// WithAbsolutePaths set policy to entries with absolute names, default reject them
+func WithAbsolutePaths(policy AbsolutePathPolicy) ExtractOption {
+	return func(config *extractConfig) { config.absolutePaths = policy }
+}
+
+// WithWindowsNames set policy to names not valid on Windows, default is [WindowsNamesAuto]
+func WithWindowsNames(policy WindowsNamePolicy) ExtractOption {
+	return func(config *extractConfig) { config.windowsNames = policy }
+}
+
+// NoFollowSymlinks refuse to write through symlinks already in dir, even pointing inside it,
+// returning [ErrUnsafePath]. Symlinks pointing outside dir are always refused by [os.Root].
+func NoFollowSymlinks() ExtractOption {
+	return func(config *extractConfig) { config.noSymlinks = true }
+}
+
+// WithInspector call fn with content of each file before it is written, like to virus
+// or license scanning. Error returned by fn stop extraction before file is created.
+// Content is held until fn return, in memory up to 4MiB and bigger entries in temporary file,
+// and rest of content not read by fn is still written.
+func WithInspector(fn func(name string, r io.Reader) error) ExtractOption {
+	return func(config *extractConfig) { config.inspector = fn }
+}
+
+// WithProgress call fn with [ProgressExtract] phase as content is written to disk,
+// by uncompressed bytes of all entries
+func WithProgress(fn ProgressFunc) ExtractOption {
+	return func(config *extractConfig) { config.progress = fn }
+}
+
+// WithFilter extract only entries fn return true, parent folders of
+// extracted entries are still created
+func WithFilter(fn func(file *File) bool) ExtractOption {
+	return func(config *extractConfig) { config.filter = fn }
+}
+
+// WithOverwrite set policy to files already in dir, default is [OverwriteReplace]
+func WithOverwrite(policy OverwritePolicy) ExtractOption {
+	return func(config *extractConfig) { config.overwrite = policy }
+}
+
+// PreserveMode set files and folders permissions from entries flags,
+// instead of default 0666 and 0755 masked by umask
+func PreserveMode() ExtractOption {
+	return func(config *extractConfig) { config.preserveMode = true }
+}
+
+// PreserveTimes set files and folders modification time from entries timestamp.
+// Times are set by path after entry is written, folders after all entries.
+func PreserveTimes() ExtractOption {
+	return func(config *extractConfig) { config.preserveTimes = true }
+}
+
+// OnExtracted call fn after each entry is written to disk
+func OnExtracted(fn func(file *File, path string)) ExtractOption {
+	return func(config *extractConfig) { config.onExtracted = fn }
+}
+
+// Extract write archive files to dir, creating it if not exists.
+//
+// Entries with names escaping dir with ".." are rejected with [ErrUnsafePath], absolute
+// names are handled by [WithAbsolutePaths] policy, and files are created through [os.Root] so symlinks in dir can't redirect writes outside it.
+// Names not valid on Windows are handled by [WithWindowsNames] policy, and existing files by [WithOverwrite] policy.
+// Errors are [*fs.PathError] with entry name as path.
+func Extract(phar *Phar, dir string, opts ...ExtractOption) error {
+	var config extractConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	files := phar.Files
+	if config.filter != nil {
+		files = slices.DeleteFunc(slices.Clone(files), func(file *File) bool { return !config.filter(file) })
+	}
+
+	// Check all names before write anything
+	targets, err := config.targetNames(files)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	var total int64
+	for _, file := range files {
+		total += file.SizeUncompressed
+	}
+	progress := newProgressCounter(config.progress, ProgressExtract, total)
+
+	chunks, done := make(chan extractChunk, 16), make(chan struct{})
+	defer close(done)
+	go decodeEntries(files, config.inspector, chunks, done)
+
+	// Folders mode and times are set after all entries, so read-only folders still receive files
+	var dirs []*File
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if config.overwrite != OverwriteReplace {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	}
+
+	var w *os.File
+	defer func() {
+		if w != nil {
+			w.Close()
+		}
+	}()
+
+	for chunk := range chunks {
+		file, target := chunk.file, targets[chunk.file]
+		switch {
+		case chunk.err != nil:
+			return &fs.PathError{Op: "extract", Path: file.Filename, Err: chunk.err}
+		case chunk.start && file.FileInfo().IsDir():
+			if isAbsoluteTarget(target) {
+				err = os.MkdirAll(filepath.FromSlash(target), 0755)
+			} else if err = config.checkSymlinks(root, target); err == nil {
+				err = mkdirAll(root, target)
+			}
+			if err != nil {
+				return &fs.PathError{Op: "extract", Path: file.Filename, Err: err}
+			} else if config.onExtracted != nil {
+				config.onExtracted(file, targetPath(dir, target))
+			}
+			dirs = append(dirs, file)
+		case chunk.start:
+			if isAbsoluteTarget(target) {
+				if err = os.MkdirAll(filepath.Dir(filepath.FromSlash(target)), 0755); err == nil {
+					w, err = os.OpenFile(filepath.FromSlash(target), flags, 0666)
+				}
+			} else if err = config.checkSymlinks(root, target); err == nil {
+				if err = mkdirAll(root, path.Dir(target)); err == nil {
+					w, err = root.OpenFile(filepath.FromSlash(target), flags, 0666)
+				}
+			}
+			if errors.Is(err, fs.ErrExist) && config.overwrite == OverwriteSkip {
+				w, err = nil, nil
+			} else if err == nil && config.preserveMode {
+				err = w.Chmod(file.FileInfo().Mode().Perm())
+			}
+			if err != nil {
+				return &fs.PathError{Op: "extract", Path: file.Filename, Err: err}
+			}
+		case chunk.data != nil && w == nil: // Skipped existing file
+			copyBufferPool.Put(chunk.data)
+			progress.add(int64(chunk.n))
+		case chunk.data != nil:
+			n, err := w.Write((*chunk.data)[:chunk.n])
+			copyBufferPool.Put(chunk.data)
+			if err != nil {
+				return &fs.PathError{Op: "extract", Path: file.Filename, Err: err}
+			}
+			progress.add(int64(n))
+		case chunk.end && w != nil:
+			err := w.Close()
+			if w = nil; err == nil && config.preserveTimes {
+				err = chtimes(root, dir, target, file.Timestamp)
+			}
+			if err != nil {
+				return &fs.PathError{Op: "extract", Path: file.Filename, Err: err}
+			} else if config.onExtracted != nil {
+				config.onExtracted(file, targetPath(dir, target))
+			}
+		}
+	}
+
+	// Deepest folders first, so parents times are not changed by children
+	for _, file := range slices.Backward(dirs) {
+		if err := config.restoreDir(root, dir, targets[file], file); err != nil {
+			return &fs.PathError{Op: "extract", Path: file.Filename, Err: err}
+		}
+	}
+	return nil
+}
+
+// restoreDir set folder mode and times from entry after its content is written
+func (config *extractConfig) restoreDir(root *os.Root, dir, target string, file *File) error {
+	if config.preserveMode {
+		var f *os.File
+		var err error
+		if isAbsoluteTarget(target) {
+			f, err = os.Open(filepath.FromSlash(target))
+		} else {
+			f, err = root.Open(filepath.FromSlash(target))
+		}
+		if err != nil {
+			return err
+		}
+		err = f.Chmod(file.FileInfo().Mode().Perm())
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if config.preserveTimes {
+		return chtimes(root, dir, target, file.Timestamp)
+	}
+	return nil
+}
+
+// chtimes set target modification and access time, refusing symlinks swapped in folder after it was written.
+// Target is checked through root, and path on disk must be same file, as Go 1.24 [os.Root] can't change times
+func chtimes(root *os.Root, dir, target string, mtime time.Time) error {
+	var info fs.FileInfo
+	var err error
+	if isAbsoluteTarget(target) {
+		info, err = os.Lstat(filepath.FromSlash(target))
+	} else {
+		info, err = root.Lstat(filepath.FromSlash(target))
+	}
+	if err != nil {
+		return err
+	} else if info.Mode()&fs.ModeSymlink != 0 {
+		return fmt.Errorf("%w: %q is symlink", ErrUnsafePath, target)
+	}
+
+	name := targetPath(dir, target)
+	if stat, err := os.Lstat(name); err != nil {
+		return err
+	} else if !os.SameFile(info, stat) {
+		return fmt.Errorf("%w: %q changed during extraction", ErrUnsafePath, target)
+	}
+	return os.Chtimes(name, mtime, mtime)
+}
+
+// targetNames check entries names and return name to create each file in extraction folder
+func (config *extractConfig) targetNames(files []*File) (map[*File]string, error) {
+	checkWindows := config.windowsNames == WindowsNamesReject || (config.windowsNames == WindowsNamesAuto && runtime.GOOS == "windows")
+	targets, used := make(map[*File]string, len(files)), make(map[string]*File, len(files))
+	for _, file := range files {
+		target, err := applyAbsolutePolicy(file.Filename, config.absolutePaths)
+		if err != nil {
+			return nil, &fs.PathError{Op: "extract", Path: file.Filename, Err: err}
+		} else if isAbsoluteTarget(target) {
+			targets[file] = target
+			continue
+		} else if err := checkEntryPath(target); err != nil {
+			return nil, &fs.PathError{Op: "extract", Path: file.Filename, Err: err}
+		}
+
+		switch {
+		case checkWindows:
+			if err := checkWindowsName(target); err != nil {
+				return nil, &fs.PathError{Op: "extract", Path: file.Filename, Err: err}
+			}
+		case config.windowsNames == WindowsNamesMangle:
+			target = mangleWindowsName(target)
+			if other, ok := used[target]; ok && other.Filename != file.Filename {
+				return nil, &fs.PathError{Op: "extract", Path: file.Filename, Err: fmt.Errorf("%w: mangled name %q already used by %q", ErrWindowsName, target, other.Filename)}
+			}
+			used[target] = file
+		}
+		targets[file] = target
+	}
+	return targets, nil
+}
+
+// targetPath return path of target on disk
+func targetPath(dir, target string) string {
+	if isAbsoluteTarget(target) {
+		return filepath.FromSlash(target)
+	}
+	return filepath.Join(dir, target)
+}
+
+// isAbsoluteTarget report if target is absolute path to current system
+func isAbsoluteTarget(target string) bool {
+	return filepath.IsAbs(filepath.FromSlash(target))
+}
+
+// checkEntryPath return [ErrUnsafePath] if name is not local to extraction folder
+func checkEntryPath(name string) error {
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	return nil
+}
+
+// checkSymlinks return [ErrUnsafePath] if [NoFollowSymlinks] is set and any existing
+// component of target in root is symlink
+func (config *extractConfig) checkSymlinks(root *os.Root, target string) error {
+	if !config.noSymlinks {
+		return nil
+	}
+
+	var name string
+	for component := range strings.SplitSeq(target, "/") {
+		name = path.Join(name, component)
+		stat, err := root.Lstat(filepath.FromSlash(name))
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		} else if err != nil {
+			return err
+		} else if stat.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("%w: %q is symlink", ErrUnsafePath, name)
+		}
+	}
+	return nil
+}
+
+// mkdirAll create folder and parents inside root
+func mkdirAll(root *os.Root, name string) error {
+	if name == "." {
+		return nil
+	} else if err := mkdirAll(root, path.Dir(name)); err != nil {
+		return err
+	}
+
+	if err := root.Mkdir(filepath.FromSlash(name), 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	return nil
+}
+
+// spoolMemoryLimit is content held in memory by [spool], bigger content is moved to temporary file
+const spoolMemoryLimit = 4 << 20
+
+// spool hold content written to it, in memory up to spoolMemoryLimit and in temporary file after
+type spool struct {
+	buff bytes.Buffer
+	file *os.File
+}
+
+func (held *spool) Write(p []byte) (int, error) {
+	if held.file == nil && held.buff.Len()+len(p) > spoolMemoryLimit {
+		file, err := os.CreateTemp("", "phargo-inspect-*")
+		if err != nil {
+			return 0, err
+		}
+		held.file = file
+		if _, err := held.buff.WriteTo(file); err != nil {
+			return 0, err
+		}
+	}
+	if held.file != nil {
+		return held.file.Write(p)
+	}
+	return held.buff.Write(p)
+}
+
+// Reader return held content from start
+func (held *spool) Reader() (io.Reader, error) {
+	if held.file == nil {
+		return &held.buff, nil
+	} else if _, err := held.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return held.file, nil
+}
+
+// Close remove temporary file
+func (held *spool) Close() error {
+	if held.file == nil {
+		return nil
+	}
+	file := held.file
+	held.file = nil
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// extractChunk is entry event sent from decoder to disk writer
+type extractChunk struct {
+	file  *File
+	start bool    // Entry begin, create file or folder
+	end   bool    // Entry end, close file
+	data  *[]byte // Decompressed content from copyBufferPool
+	n     int
+	err   error
+}
+
+// decodeEntries send entries content to chunks until all files decoded or done is closed,
+// so decompression overlap disk writes. Files content is sent only after inspector accept it.
+func decodeEntries(files []*File, inspector func(name string, r io.Reader) error, chunks chan<- extractChunk, done <-chan struct{}) {
+	defer close(chunks)
+	send := func(chunk extractChunk) bool {
+		select {
+		case chunks <- chunk:
+			return true
+		case <-done:
+			if chunk.data != nil {
+				copyBufferPool.Put(chunk.data)
+			}
+			return false
+		}
+	}
+
+	// Inspected content of current file
+	held := &spool{}
+	defer func() { held.Close() }()
+
+	for _, file := range files {
+		if file.FileInfo().IsDir() {
+			if !send(extractChunk{file: file, start: true}) {
+				return
+			}
+			continue
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			send(extractChunk{file: file, err: err})
+			return
+		}
+
+		var content io.Reader = r
+		if inspector != nil {
+			held.Close()
+			held = &spool{}
+			if err := inspector(file.Filename, io.TeeReader(r, held)); err != nil {
+				r.Close()
+				send(extractChunk{file: file, err: err})
+				return
+			} else if _, err := copyBuffer(held, r); err != nil {
+				r.Close()
+				send(extractChunk{file: file, err: err})
+				return
+			} else if content, err = held.Reader(); err != nil {
+				r.Close()
+				send(extractChunk{file: file, err: err})
+				return
+			}
+		}
+
+		if !send(extractChunk{file: file, start: true}) {
+			r.Close()
+			return
+		}
+
+		for {
+			buff := copyBufferPool.Get().(*[]byte)
+			n, err := fillBuffer(content, *buff)
+			if n > 0 && !send(extractChunk{file: file, data: buff, n: n}) {
+				r.Close()
+				return
+			} else if n == 0 {
+				copyBufferPool.Put(buff)
+			}
+
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				r.Close()
+				send(extractChunk{file: file, err: err})
+				return
+			}
+		}
+		r.Close()
+
+		if !send(extractChunk{file: file, end: true}) {
+			return
+		}
+	}
+}
+
+// fillBuffer read from r until buff is full or r return error
+func fillBuffer(r io.Reader, buff []byte) (n int, err error) {
+	for n < len(buff) && err == nil {
+		var read int
+		read, err = r.Read(buff[n:])
+		n += read
+	}
+	return n, err
+}
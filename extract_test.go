@@ -0,0 +1,496 @@
+package phargo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExtract(t *testing.T) {
+	osFile, err := os.Open("./testdata/metadata_dir_sha256.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+
+	file, err := NewReaderFromFile(osFile)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	dir := t.TempDir()
+	if err := Extract(file, dir); err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "DIR1", "FILE2"))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if string(data) != "D1_DATA12" {
+		t.Error("Wrong DIR1/FILE2 content")
+		return
+	}
+}
+
+func TestExtractEmptyFile(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "empty.txt"},
+		testEntry{name: "dir/", flags: EntryPermDefDir},
+		testEntry{name: "dir/empty"},
+	)
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	dir := t.TempDir()
+	if err := Extract(file, dir); err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	for name, isDir := range map[string]bool{"empty.txt": false, "dir": true, "dir/empty": false} {
+		stat, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		} else if stat.IsDir() != isDir || (!isDir && stat.Size() != 0) {
+			t.Errorf("Wrong %s extracted: %s", name, stat.Mode())
+			return
+		}
+	}
+}
+
+func TestExtractTraversal(t *testing.T) {
+	for _, name := range []string{"../escape.txt", "a/../../escape.txt", "/tmp/escape.txt"} {
+		data := buildPhar(testEntry{name: name, data: []byte("ESCAPE")})
+		file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		}
+
+		dir := t.TempDir()
+		if err := Extract(file, filepath.Join(dir, "out")); !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("Should get ErrUnsafePath to %q, got %v", name, err)
+			return
+		} else if _, err := os.Stat(filepath.Join(dir, "escape.txt")); err == nil {
+			t.Errorf("%q escaped extraction folder", name)
+			return
+		}
+	}
+}
+
+func TestExtractSymlink(t *testing.T) {
+	dir, outside := t.TempDir(), t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skip(err)
+		return
+	}
+
+	data := buildPhar(testEntry{name: "link/escape.txt", data: []byte("ESCAPE")})
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	if err := Extract(file, dir); err == nil {
+		t.Error("Should get error writing through symlink")
+		return
+	} else if _, err := os.Stat(filepath.Join(outside, "escape.txt")); err == nil {
+		t.Error("File written outside extraction folder")
+		return
+	}
+}
+
+func TestWindowsNames(t *testing.T) {
+	for name, mangled := range map[string]string{
+		"dir/file.txt":   "",
+		`a\b.php`:        "a_b.php",
+		"con.txt":        "_con.txt",
+		"dir/NUL":        "dir/_NUL",
+		"trailing. ":     "trailing__",
+		"what?.php":      "what_.php",
+		"COM1/file.php":  "_COM1/file.php",
+		"console.php":    "",
+		"dir./file.php ": "dir_/file.php_",
+	} {
+		if err := checkWindowsName(name); (err == nil) != (mangled == "") {
+			t.Errorf("Wrong check to %q: %v", name, err)
+			return
+		} else if mangled == "" {
+			mangled = name
+		}
+
+		if got := mangleWindowsName(name); got != mangled {
+			t.Errorf("Wrong mangle to %q: expect %q, got %q", name, mangled, got)
+			return
+		} else if err := checkWindowsName(got); err != nil {
+			t.Errorf("Mangled %q still invalid: %s", got, err)
+			return
+		}
+	}
+
+	data := buildPhar(testEntry{name: "aux.php", data: []byte("AUX")})
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	if err := Extract(file, t.TempDir(), WithWindowsNames(WindowsNamesReject)); !errors.Is(err, ErrWindowsName) {
+		t.Errorf("Should get ErrWindowsName, got %v", err)
+		return
+	}
+
+	dir := t.TempDir()
+	if err := Extract(file, dir, WithWindowsNames(WindowsNamesMangle)); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if _, err := os.Stat(filepath.Join(dir, "_aux.php")); err != nil {
+		t.Error("Mangled file not created", err)
+		return
+	}
+}
+
+func TestAbsolutePaths(t *testing.T) {
+	for name, stripped := range map[string]string{
+		"/etc/passwd":          "etc/passwd",
+		`C:\Windows\win.ini`:   "Windows/win.ini",
+		"c:/boot.ini":          "boot.ini",
+		`\\server\share\a.txt`: "server/share/a.txt",
+	} {
+		if !isAbsoluteName(name) {
+			t.Errorf("%q should be absolute", name)
+			return
+		} else if got, err := applyAbsolutePolicy(name, AbsolutePathsStrip); err != nil || got != stripped {
+			t.Errorf("Wrong strip to %q: expect %q, got %q (%v)", name, stripped, got, err)
+			return
+		} else if _, err := applyAbsolutePolicy(name, AbsolutePathsReject); !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("Should reject %q", name)
+			return
+		}
+	}
+
+	data := buildPhar(testEntry{name: "/etc/passwd", data: []byte("ROOT")})
+	file, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{AbsolutePaths: AbsolutePathsStrip})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if file.Files[0].Filename != "etc/passwd" {
+		t.Errorf("Reader should strip name, got %q", file.Files[0].Filename)
+		return
+	}
+
+	if _, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{AbsolutePaths: AbsolutePathsReject}); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("Reader should reject absolute name, got %v", err)
+		return
+	}
+
+	if file, err = NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if err := Extract(file, t.TempDir()); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("Extract should reject absolute name, got %v", err)
+		return
+	}
+
+	dir := t.TempDir()
+	if err := Extract(file, dir, WithAbsolutePaths(AbsolutePathsStrip)); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if _, err := os.Stat(filepath.Join(dir, "etc", "passwd")); err != nil {
+		t.Error("Stripped file not created", err)
+		return
+	}
+}
+
+func TestNoFollowSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "real"), 0755); err != nil {
+		t.Error(err)
+		return
+	} else if err := os.Symlink("real", filepath.Join(dir, "link")); err != nil {
+		t.Skip(err)
+		return
+	}
+
+	data := buildPhar(testEntry{name: "link/file.txt", data: []byte("DATA")})
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	if err := Extract(file, dir, NoFollowSymlinks()); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("Should get ErrUnsafePath writing through symlink, got %v", err)
+		return
+	} else if err := Extract(file, dir); err != nil {
+		t.Error("Should follow symlink inside extraction folder, got", err)
+		return
+	} else if _, err := os.Stat(filepath.Join(dir, "real", "file.txt")); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestWithInspector(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "clean.txt", data: []byte("CLEAN")},
+		testEntry{name: "virus.txt", data: []byte("EICAR")},
+	)
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	errVirus := errors.New("virus found")
+	inspector := func(name string, r io.Reader) error {
+		if content, _ := io.ReadAll(r); string(content) == "EICAR" {
+			return errVirus
+		}
+		return nil
+	}
+
+	dir := t.TempDir()
+	if err := Extract(file, dir, WithInspector(inspector)); !errors.Is(err, errVirus) {
+		t.Errorf("Should get inspector error, got %v", err)
+		return
+	} else if content, err := os.ReadFile(filepath.Join(dir, "clean.txt")); err != nil || string(content) != "CLEAN" {
+		t.Errorf("clean.txt should be extracted, got %q, %v", content, err)
+		return
+	} else if _, err := os.Stat(filepath.Join(dir, "virus.txt")); err == nil {
+		t.Error("virus.txt written to disk")
+		return
+	}
+
+	// Content not read by inspector is still written
+	dir = t.TempDir()
+	if err := Extract(file, dir, WithInspector(func(string, io.Reader) error { return nil })); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if content, err := os.ReadFile(filepath.Join(dir, "virus.txt")); err != nil || string(content) != "EICAR" {
+		t.Errorf("virus.txt should be extracted, got %q, %v", content, err)
+		return
+	}
+}
+
+func TestWithInspectorSpool(t *testing.T) {
+	big := bytes.Repeat([]byte("0123456789abcdef"), spoolMemoryLimit/16*2+1)
+	data := buildPhar(testEntry{name: "small.txt", data: []byte("SMALL")}, testEntry{name: "big.bin", data: big})
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	// Inspector read only head, big entry is held in temporary file
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	inspector := func(name string, r io.Reader) error {
+		_, err := io.CopyN(io.Discard, r, 1024)
+		if err == io.EOF {
+			err = nil
+		}
+		return err
+	}
+
+	dir := t.TempDir()
+	if err := Extract(file, dir, WithInspector(inspector)); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if content, err := os.ReadFile(filepath.Join(dir, "big.bin")); err != nil || !bytes.Equal(content, big) {
+		t.Errorf("Wrong big.bin content, %d bytes, %v", len(content), err)
+		return
+	} else if entries, _ := os.ReadDir(tmp); len(entries) != 0 {
+		t.Errorf("Temporary files not removed: %v", entries)
+		return
+	}
+}
+
+func TestExtractOptions(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "README", data: []byte("NEW")},
+		testEntry{name: "bin/", flags: EntryPermDefDir},
+		testEntry{name: "bin/tool", data: []byte("TOOL"), flags: 0755},
+	)
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	modTime := time.Unix(1700000000, 0)
+	for _, entry := range file.Files {
+		entry.Timestamp = modTime
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("OLD"), 0644); err != nil {
+		t.Error(err)
+		return
+	}
+
+	if err := Extract(file, dir, WithOverwrite(OverwriteReject)); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("Should get fs.ErrExist, got %v", err)
+		return
+	} else if err := Extract(file, dir, WithOverwrite(OverwriteSkip), PreserveMode(), PreserveTimes()); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if content, _ := os.ReadFile(filepath.Join(dir, "README")); string(content) != "OLD" {
+		t.Errorf("README should be kept, got %q", content)
+		return
+	}
+
+	stat, err := os.Stat(filepath.Join(dir, "bin", "tool"))
+	if err != nil {
+		t.Error(err)
+		return
+	} else if stat.Mode().Perm() != 0755 {
+		t.Errorf("Wrong bin/tool mode: %s", stat.Mode())
+		return
+	} else if !stat.ModTime().Equal(modTime) {
+		t.Errorf("Wrong bin/tool time: %s", stat.ModTime())
+		return
+	} else if stat, err = os.Stat(filepath.Join(dir, "bin")); err != nil || !stat.ModTime().Equal(modTime) {
+		t.Errorf("Wrong bin time: %v", err)
+		return
+	}
+
+	dir = t.TempDir()
+	onlyBin := func(file *File) bool { return file.Filename == "bin/tool" }
+	if err := Extract(file, dir, WithFilter(onlyBin)); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if _, err := os.Stat(filepath.Join(dir, "README")); err == nil {
+		t.Error("README should be filtered")
+		return
+	} else if _, err := os.Stat(filepath.Join(dir, "bin", "tool")); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+// extractSequential write entries one by one, decompressing and writing in same goroutine,
+// baseline to compare with [Extract] pipeline
+func extractSequential(phar *Phar, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	for _, file := range phar.Files {
+		if err := mkdirAll(root, path.Dir(file.Filename)); err != nil {
+			return err
+		}
+		r, err := file.Open()
+		if err != nil {
+			return err
+		}
+		w, err := root.Create(file.Filename)
+		if err != nil {
+			r.Close()
+			return err
+		}
+		_, err = copyBuffer(w, r)
+		r.Close()
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func BenchmarkExtract(b *testing.B) {
+	osFile, err := os.Open("./testdata/phpDocumentor.phar")
+	if err != nil {
+		b.Skip(err)
+		return
+	}
+	defer osFile.Close()
+
+	file, err := NewReaderFromFile(osFile)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var total int64
+	for _, entry := range file.Files {
+		total += entry.SizeUncompressed
+	}
+
+	for name, extract := range map[string]func(*Phar, string) error{
+		"pipelined":  func(phar *Phar, dir string) error { return Extract(phar, dir) },
+		"sequential": extractSequential,
+	} {
+		b.Run(name, func(b *testing.B) {
+			dir := filepath.Join(b.TempDir(), "out")
+			b.SetBytes(total)
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := extract(file, dir); err != nil {
+					b.Fatal(err)
+				}
+				b.StopTimer()
+				if err := os.RemoveAll(dir); err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+func TestChtimesSymlink(t *testing.T) {
+	dir, outside := t.TempDir(), t.TempDir()
+	target := filepath.Join(outside, "target")
+	if err := os.WriteFile(target, []byte("OUT"), 0644); err != nil {
+		t.Error(err)
+		return
+	}
+	if err := os.Symlink(target, filepath.Join(dir, "link")); err != nil {
+		t.Skip(err)
+		return
+	} else if err := os.Symlink(outside, filepath.Join(dir, "sub")); err != nil {
+		t.Skip(err)
+		return
+	}
+	before, _ := os.Stat(target)
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer root.Close()
+
+	// Symlinks swapped in folder after entry is written
+	mtime := time.Unix(1000000000, 0)
+	if err := chtimes(root, dir, "link", mtime); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("Should get ErrUnsafePath to symlink, got %v", err)
+		return
+	} else if err := chtimes(root, dir, "sub/target", mtime); err == nil {
+		t.Error("Should not change times through symlink folder")
+		return
+	} else if after, _ := os.Stat(target); !after.ModTime().Equal(before.ModTime()) {
+		t.Error("Outside file time changed")
+		return
+	}
+}
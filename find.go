@@ -0,0 +1,39 @@
+package phargo
+
+import (
+	"strings"
+	"time"
+)
+
+// Find return entries matching predicate, in manifest order
+func (phar *Phar) Find(match func(*File) bool) []*File {
+	var files []*File
+	for _, file := range phar.Files {
+		if match(file) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// BySuffix match entries with name ending with any of suffixes, like ".php"
+func BySuffix(suffixes ...string) func(*File) bool {
+	return func(file *File) bool {
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(file.Filename, suffix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// LargerThan match entries with uncompressed size above size bytes
+func LargerThan(size int64) func(*File) bool {
+	return func(file *File) bool { return file.SizeUncompressed > size }
+}
+
+// ModifiedAfter match entries with timestamp after t
+func ModifiedAfter(t time.Time) func(*File) bool {
+	return func(file *File) bool { return file.Timestamp.After(t) }
+}
@@ -0,0 +1,33 @@
+package phargo
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestFind(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "src/app.php", data: []byte("<?php echo 1;")},
+		testEntry{name: "README.md", data: []byte("readme")},
+		testEntry{name: "src/lib.php", data: []byte("<?php")},
+	)
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	for index, entry := range file.Files {
+		entry.Timestamp = time.Unix(int64(index)*500, 0)
+	}
+
+	if found := file.Find(BySuffix(".php")); len(found) != 2 || found[0].Filename != "src/app.php" || found[1].Filename != "src/lib.php" {
+		t.Errorf("BySuffix found %d entries", len(found))
+	}
+	if found := file.Find(LargerThan(6)); len(found) != 1 || found[0].Filename != "src/app.php" {
+		t.Errorf("LargerThan found %d entries", len(found))
+	}
+	if found := file.Find(ModifiedAfter(time.Unix(500, 0))); len(found) != 1 || found[0].Filename != "src/lib.php" {
+		t.Errorf("ModifiedAfter found %d entries", len(found))
+	}
+}
@@ -0,0 +1,80 @@
+package phargo
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+const (
+	GlobalHasZlib  GlobalFlags = 0x00001000 // Some entries are gzip compressed
+	GlobalHasBzip2 GlobalFlags = 0x00002000 // Some entries are bzip2 compressed
+	GlobalSigned   GlobalFlags = 0x00010000 // Archive has signature after content
+)
+
+var globalFlagName = []struct {
+	flag GlobalFlags
+	name string
+}{
+	{GlobalSigned, "signed"},
+	{GlobalHasZlib, "zlib"},
+	{GlobalHasBzip2, "bzip2"},
+}
+
+// GlobalFlags is archive flags bitmap from manifest
+type GlobalFlags uint32
+
+// String return set flags joined with "|", like "signed|zlib", unknown bits in hex
+func (flags GlobalFlags) String() string {
+	if flags == 0 {
+		return "none"
+	}
+
+	var names []string
+	for _, known := range globalFlagName {
+		if flags&known.flag != 0 {
+			names = append(names, known.name)
+			flags &^= known.flag
+		}
+	}
+	if flags != 0 {
+		names = append(names, fmt.Sprintf("%#x", uint32(flags)))
+	}
+	return strings.Join(names, "|")
+}
+
+func (flags GlobalFlags) MarshalText() (text []byte, err error) {
+	return []byte(flags.String()), nil
+}
+
+var compressionName = map[EntryFlags]string{
+	EntryCompressedNone:  "none",
+	EntryCompressedGzip:  "gzip",
+	EntryCompressedBzip2: "bzip2",
+}
+
+// EntryFlags is entry flags bitmap from manifest, with permission bits and compression
+type EntryFlags uint32
+
+// Perm return entry permission bits
+func (flags EntryFlags) Perm() fs.FileMode { return fs.FileMode(flags & EntryPermMask) }
+
+// Compression return compression bits, like [EntryCompressedGzip]
+func (flags EntryFlags) Compression() EntryFlags { return flags & CompressionMask }
+
+// String return permission and compression, like "0644|gzip", unknown bits in hex
+func (flags EntryFlags) String() string {
+	name, ok := compressionName[flags.Compression()]
+	if !ok {
+		name = fmt.Sprintf("%#x", uint32(flags.Compression()))
+	}
+	str := fmt.Sprintf("%04o|%s", uint32(flags.Perm()), name)
+	if unknown := flags &^ (EntryPermMask | CompressionMask); unknown != 0 {
+		str += fmt.Sprintf("|%#x", uint32(unknown))
+	}
+	return str
+}
+
+func (flags EntryFlags) MarshalText() (text []byte, err error) {
+	return []byte(flags.String()), nil
+}
@@ -0,0 +1,40 @@
+package phargo
+
+import "testing"
+
+func TestGlobalFlags(t *testing.T) {
+	for flags, str := range map[GlobalFlags]string{
+		0:                            "none",
+		GlobalSigned:                 "signed",
+		GlobalSigned | GlobalHasZlib: "signed|zlib",
+		GlobalHasBzip2 | 0x4:         "bzip2|0x4",
+	} {
+		if got, _ := flags.MarshalText(); string(got) != str {
+			t.Errorf("Wrong text to %#x: expect %q, got %q", uint32(flags), str, got)
+			return
+		}
+	}
+}
+
+func TestEntryFlags(t *testing.T) {
+	flags := EntryFlags(0755 | EntryCompressedGzip)
+	if flags.Perm() != 0755 {
+		t.Errorf("Wrong perm: %s", flags.Perm())
+		return
+	} else if flags.Compression() != EntryCompressedGzip {
+		t.Errorf("Wrong compression: %#x", uint32(flags.Compression()))
+		return
+	}
+
+	for flags, str := range map[EntryFlags]string{
+		EntryPermDefFile:            "0666|none",
+		0644 | EntryCompressedBzip2: "0644|bzip2",
+		0600 | 0x4000:               "0600|0x4000",
+		EntryPermDefFile | EntryCompressedGzip | 0x10000: "0666|gzip|0x10000",
+	} {
+		if got, _ := flags.MarshalText(); string(got) != str {
+			t.Errorf("Wrong text to %#x: expect %q, got %q", uint32(flags), str, got)
+			return
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package phargo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	_ fs.FS         = (*Phar)(nil)
+	_ fs.ReadDirFS  = (*Phar)(nil)
+	_ fs.StatFS     = (*Phar)(nil)
+	_ fs.ReadFileFS = (*Phar)(nil)
+)
+
+// Open opens the named file or directory from archive, implementing [fs.FS].
+//
+// Directories not present in manifest are synthesized from the entry paths.
+func (phar *Phar) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	} else if phar.caseInsensitive {
+		name = phar.foldName(name)
+	}
+
+	if file := phar.lookup(name); file != nil && !file.FileInfo().IsDir() {
+		return &openFile{file: file}, nil
+	}
+
+	entries, ok := phar.readDir(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &openDir{name: name, info: phar.dirStat(name), entries: entries}, nil
+}
+
+// ReadDir return entries of named folder sorted by name, implementing [fs.ReadDirFS]
+func (phar *Phar) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	} else if phar.caseInsensitive {
+		name = phar.foldName(name)
+	}
+
+	if file := phar.lookup(name); file != nil && !file.FileInfo().IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	entries, ok := phar.readDir(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return entries, nil
+}
+
+// Stat return info of named file or folder without open it, implementing [fs.StatFS]
+func (phar *Phar) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	} else if phar.caseInsensitive {
+		name = phar.foldName(name)
+	}
+
+	if file := phar.lookup(name); file != nil && !file.FileInfo().IsDir() {
+		return file.FileInfo(), nil
+	} else if _, ok := phar.readDir(name); !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return phar.dirStat(name), nil
+}
+
+// dirStat return info of folder entry in manifest, or synthesized info if folder only exists in entry paths
+func (phar *Phar) dirStat(name string) fs.FileInfo {
+	if file := phar.lookup(name); file != nil && file.FileInfo().IsDir() {
+		return file.FileInfo()
+	}
+	return dirInfo{name}
+}
+
+// ReadFile return decompressed content of named file, implementing [fs.ReadFileFS]
+func (phar *Phar) ReadFile(name string) ([]byte, error) {
+	f, err := phar.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	file, ok := f.(*openFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	var content bytes.Buffer
+	content.Grow(int(min(file.file.SizeUncompressed, readAtChunk)))
+	if _, err := copyBuffer(&content, file); err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return content.Bytes(), nil
+}
+
+// fsIndex map names to entries and folders to children, so [fs.FS] methods don't scan all entries
+type fsIndex struct {
+	files    []*File             // Files indexed, index is rebuilt if Phar.Files length or array change
+	byName   map[string]*File    // First entry with name
+	children map[string][]string // Folder to sorted children names
+	folded   map[string]string   // Lower case name to first entry or folder name in byte order
+}
+
+// fsIndex return index of phar.Files, building it on first use or after Files change
+func (phar *Phar) fsIndex() *fsIndex {
+	phar.indexMutex.Lock()
+	defer phar.indexMutex.Unlock()
+	if index := phar.index; index != nil && len(index.files) == len(phar.Files) && (len(index.files) == 0 || &index.files[0] == &phar.Files[0]) {
+		return index
+	}
+
+	index := &fsIndex{files: phar.Files, byName: map[string]*File{}, children: map[string][]string{}, folded: map[string]string{}}
+	addFolded := func(name string) {
+		key := strings.ToLower(name)
+		if match, ok := index.folded[key]; !ok || name < match {
+			index.folded[key] = name
+		}
+	}
+	seen := map[string]bool{}
+	for _, file := range phar.Files {
+		if _, ok := index.byName[file.Filename]; !ok {
+			index.byName[file.Filename] = file
+			addFolded(file.Filename)
+		}
+
+		// Each folder in path list next component as child
+		dir, rest := ".", file.Filename
+		for {
+			child, next, more := strings.Cut(rest, "/")
+			if child == "" {
+				break
+			}
+			name := path.Join(dir, child)
+			if !seen[name] {
+				seen[name] = true
+				index.children[dir] = append(index.children[dir], child)
+				addFolded(name)
+			}
+			if !more {
+				break
+			}
+			dir, rest = name, next
+		}
+	}
+	for _, children := range index.children {
+		slices.Sort(children)
+	}
+	phar.index = index
+	return index
+}
+
+// lookup return file entry with same name
+func (phar *Phar) lookup(name string) *File {
+	return phar.fsIndex().byName[name]
+}
+
+// foldName return name stored in archive matching name ignoring case, exact entry or
+// folder name is preferred, then first name in byte order, so result not depend on manifest order
+func (phar *Phar) foldName(name string) string {
+	index := phar.fsIndex()
+	if _, ok := index.byName[name]; ok || name == "." {
+		return name
+	} else if _, ok := index.children[name]; ok {
+		return name
+	} else if match, ok := index.folded[strings.ToLower(name)]; ok {
+		return match
+	}
+	return name
+}
+
+// readDir return sorted directory entries, ok is false if directory not exists
+func (phar *Phar) readDir(name string) (entries []fs.DirEntry, ok bool) {
+	index := phar.fsIndex()
+	children, ok := index.children[name]
+	if file := index.byName[name]; file != nil && file.FileInfo().IsDir() {
+		ok = true
+	}
+
+	entries = make([]fs.DirEntry, 0, len(children))
+	for _, child := range children {
+		childName := path.Join(name, child)
+		if _, isDir := index.children[childName]; isDir {
+			entries = append(entries, fs.FileInfoToDirEntry(phar.dirStat(childName)))
+		} else {
+			entries = append(entries, fs.FileInfoToDirEntry(index.byName[childName].FileInfo()))
+		}
+	}
+	return entries, ok || name == "."
+}
+
+// dirInfo is a [fs.FileInfo] to directories not in manifest
+type dirInfo struct {
+	name string
+}
+
+func (dir dirInfo) Name() string       { return path.Base(dir.name) }
+func (dir dirInfo) Size() int64        { return 0 }
+func (dir dirInfo) Mode() fs.FileMode  { return fs.ModeDir | 0555 }
+func (dir dirInfo) ModTime() time.Time { return time.Time{} }
+func (dir dirInfo) IsDir() bool        { return true }
+func (dir dirInfo) Sys() any           { return nil }
+
+// openDir implements [fs.ReadDirFile]
+type openDir struct {
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (dir *openDir) Stat() (fs.FileInfo, error) { return dir.info, nil }
+func (dir *openDir) Close() error               { return nil }
+func (dir *openDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: dir.name, Err: errors.New("is a directory")}
+}
+
+func (dir *openDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	entries := dir.entries[dir.offset:]
+	if count > 0 {
+		if len(entries) == 0 {
+			return nil, io.EOF
+		}
+		entries = entries[:min(count, len(entries))]
+	}
+	dir.offset += len(entries)
+	return entries, nil
+}
+
+// openFile implements [fs.File] and [io.Seeker] over entry content,
+// seeking backwards re-open entry from start
+type openFile struct {
+	file   *File
+	reader io.ReadCloser
+	pos    int64 // Current reader position
+	offset int64 // Position requested by Seek
+}
+
+func (file *openFile) Stat() (fs.FileInfo, error) { return file.file.FileInfo(), nil }
+
+func (file *openFile) Close() error {
+	if file.reader != nil {
+		return file.reader.Close()
+	}
+	return nil
+}
+
+func (file *openFile) Read(p []byte) (n int, err error) {
+	if file.reader == nil || file.pos > file.offset {
+		if file.reader != nil {
+			file.reader.Close()
+		}
+		if file.reader, err = file.file.Open(); err != nil {
+			return 0, err
+		}
+		file.pos = 0
+	}
+
+	if file.pos < file.offset {
+		skip, err := io.CopyN(io.Discard, file.reader, file.offset-file.pos)
+		file.pos += skip
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err = file.reader.Read(p)
+	file.pos += int64(n)
+	file.offset = file.pos
+	return n, err
+}
+
+func (file *openFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += file.offset
+	case io.SeekEnd:
+		offset += file.file.SizeUncompressed
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: file.file.Filename, Err: fs.ErrInvalid}
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: file.file.Filename, Err: fs.ErrInvalid}
+	}
+	file.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,258 @@
+package phargo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFS(t *testing.T) {
+	osFile, err := os.Open("./testdata/metadata_dir_sha256.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+
+	file, err := NewReaderFromFile(osFile)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	if err := fstest.TestFS(file, "FILE", "DIR1/FILE1", "DIR1/FILE2", "DIR2/FILE1"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	data, err := fs.ReadFile(file, "DIR2/FILE1")
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if string(data) != "D1_DATA21" {
+		t.Error("Wrong DIR2/FILE1 content")
+		return
+	}
+}
+
+func TestFSEmptyFile(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "empty.txt"},
+		testEntry{name: "dir/a.txt", data: []byte("AAAA")},
+		testEntry{name: "dir/", flags: EntryPermDefDir},
+		testEntry{name: "empty/", flags: EntryPermDefDir},
+	)
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	if err := fstest.TestFS(file, "empty.txt", "dir/a.txt", "empty"); err != nil {
+		t.Error(err)
+		return
+	}
+	if content, err := fs.ReadFile(file, "empty.txt"); err != nil || len(content) != 0 {
+		t.Errorf("Wrong empty.txt content: %q, %v", content, err)
+		return
+	} else if info, err := fs.Stat(file, "empty.txt"); err != nil || info.IsDir() {
+		t.Errorf("empty.txt should be file: %v", err)
+		return
+	} else if info, err := fs.Stat(file, "empty"); err != nil || !info.IsDir() {
+		t.Errorf("empty should be folder: %v", err)
+		return
+	}
+}
+
+func TestConcurrentOpen(t *testing.T) {
+	osFile, err := os.Open("./testdata/PocketMine-MP_1.4.1.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+
+	file, err := NewReaderFromFile(osFile)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	for _, cacheSize := range []int64{0, 256 * 1024} {
+		file.EnableCache(cacheSize)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 8)
+		for worker := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for index := worker; index < len(file.Files); index += 3 {
+					entry := file.Files[index]
+					if entry.FileInfo().IsDir() {
+						continue
+					}
+
+					data, err := fs.ReadFile(file, entry.Filename)
+					if err != nil {
+						errs <- err
+						return
+					} else if crc32.ChecksumIEEE(data) != entry.CRC {
+						errs <- fmt.Errorf("%s content not match CRC", entry.Filename)
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			t.Errorf("Got error with cache %d: %s", cacheSize, err)
+			return
+		}
+	}
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "src/b/App.php", data: []byte("app")},
+		testEntry{name: "Src/a.php", data: []byte("upper")},
+		testEntry{name: "README.md", data: []byte("readme")},
+		testEntry{name: "readme.md", data: []byte("lower")},
+	)
+	file, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{CaseInsensitive: true})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	for name, expected := range map[string]string{
+		"readme.md":     "lower",  // Exact name
+		"Readme.MD":     "readme", // First in byte order
+		"SRC/B/app.PHP": "app",
+		"src/A.php":     "upper",
+	} {
+		if content, err := fs.ReadFile(file, name); err != nil || string(content) != expected {
+			t.Errorf("Wrong %s content: %q, %v", name, content, err)
+			return
+		}
+	}
+
+	if entries, err := fs.ReadDir(file, "SRC"); err != nil || len(entries) != 1 || entries[0].Name() != "a.php" {
+		t.Errorf("SRC should resolve to Src, got %v, %v", entries, err)
+		return
+	}
+}
+
+func TestFSInterfaces(t *testing.T) {
+	osFile, err := os.Open("./testdata/metadata_dir_sha256.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+
+	file, err := NewReaderFromFile(osFile)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	if info, err := file.Stat("DIR1"); err != nil || !info.IsDir() {
+		t.Errorf("DIR1 should be folder: %v", err)
+		return
+	} else if info, err := file.Stat("DIR1/FILE1"); err != nil || info.Size() != 9 {
+		t.Errorf("Wrong DIR1/FILE1 stat: %v", err)
+		return
+	} else if _, err := file.Stat("missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Should get fs.ErrNotExist, got %v", err)
+		return
+	}
+
+	if entries, err := file.ReadDir("DIR1"); err != nil || len(entries) != 2 {
+		t.Errorf("Wrong DIR1 entries: %v, %v", entries, err)
+		return
+	} else if _, err := file.ReadDir("FILE"); err == nil {
+		t.Error("ReadDir of file should fail")
+		return
+	}
+
+	if content, err := file.ReadFile("DIR2/FILE1"); err != nil || string(content) != "D1_DATA21" {
+		t.Errorf("Wrong DIR2/FILE1 content: %q, %v", content, err)
+		return
+	} else if _, err := file.ReadFile("DIR2"); err == nil {
+		t.Error("ReadFile of folder should fail")
+		return
+	}
+
+	// Archive served as folder
+	server := httptest.NewServer(http.FileServerFS(file))
+	defer server.Close()
+	res, err := http.Get(server.URL + "/DIR2/FILE1")
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	defer res.Body.Close()
+	if content, _ := io.ReadAll(res.Body); string(content) != "D1_DATA21" {
+		t.Errorf("Wrong served content: %q", content)
+		return
+	}
+}
+
+func TestFSIndexRebuild(t *testing.T) {
+	data := buildPhar(testEntry{name: "a.txt", data: []byte("AAAA")}, testEntry{name: "dir/b.txt", data: []byte("BBBB")})
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	if _, err := fs.Stat(file, "dir/b.txt"); err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	file.Files = file.Files[:1]
+	if _, err := fs.Stat(file, "dir/b.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Removed entry should not exist, got %v", err)
+		return
+	} else if entries, err := fs.ReadDir(file, "."); err != nil || len(entries) != 1 {
+		t.Errorf("Wrong root entries after change: %v, %v", entries, err)
+		return
+	}
+}
+
+func BenchmarkWalkDir(b *testing.B) {
+	var entries []testEntry
+	for index := range 10_000 {
+		entries = append(entries, testEntry{name: fmt.Sprintf("src/%d/%d.php", index%100, index), data: []byte("<?php")})
+	}
+	data := buildPhar(entries...)
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		err := fs.WalkDir(file, ".", func(path string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() {
+				_, err = fs.Stat(file, path)
+			}
+			return err
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
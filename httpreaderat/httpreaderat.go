@@ -0,0 +1,92 @@
+// Package httpreaderat implement [io.ReaderAt] over HTTP Range requests,
+// to parse remote phar archives without download whole file.
+package httpreaderat
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Sirherobrine23/phargo/cachedreaderat"
+)
+
+// ErrNoRange is returned when server not support Range requests
+var ErrNoRange = errors.New("server not support range requests")
+
+// ErrStatus is returned when server reply with error status
+var ErrStatus = errors.New("unexpected http status")
+
+// ReaderAt read remote file with Range requests, caching blocks with [cachedreaderat.ReaderAt].
+// It is safe for concurrent use.
+type ReaderAt struct {
+	*cachedreaderat.ReaderAt
+	Client *http.Client
+	URL    string
+}
+
+// New check url support Range requests and return [ReaderAt] with default cache
+func New(url string) (*ReaderAt, error) {
+	return NewWithClient(http.DefaultClient, url)
+}
+
+// NewWithClient is [New] with custom http client
+func NewWithClient(client *http.Client, url string) (*ReaderAt, error) {
+	r := &ReaderAt{Client: client, URL: url}
+
+	// First byte request return file size in Content-Range
+	res, err := r.get(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	_, total, ok := strings.Cut(res.Header.Get("Content-Range"), "/")
+	if !ok {
+		return nil, fmt.Errorf("%w: missing Content-Range size", ErrNoRange)
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid Content-Range size %q", ErrNoRange, total)
+	}
+
+	r.ReaderAt = cachedreaderat.New(cachedreaderat.FetchFunc(r.fetch), size)
+	return r, nil
+}
+
+// get request bytes from start to end, inclusive
+func (r *ReaderAt) get(start, end int64) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, r.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	res, err := r.Client.Do(req)
+	if err != nil {
+		return nil, err
+	} else if res.StatusCode != http.StatusPartialContent {
+		res.Body.Close()
+		if res.StatusCode == http.StatusOK {
+			return nil, ErrNoRange
+		}
+		return nil, fmt.Errorf("%w: cannot get %s: %s", ErrStatus, r.URL, res.Status)
+	}
+	return res, nil
+}
+
+// fetch read n bytes at off with one Range request
+func (r *ReaderAt) fetch(off, n int64) ([]byte, error) {
+	res, err := r.get(off, off+n-1)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(res.Body, data); err != nil {
+		return nil, fmt.Errorf("cannot read %s bytes %d-%d: %w", r.URL, off, off+n-1, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,81 @@
+package httpreaderat
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+func TestReaderAt(t *testing.T) {
+	data, err := os.ReadFile("../testdata/simple.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.ServeContent(w, r, "simple.phar", time.Time{}, bytes.NewReader(data))
+	}))
+	defer server.Close()
+
+	r, err := New(server.URL)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if r.Size() != int64(len(data)) {
+		t.Errorf("Wrong size, expect %d, got %d", len(data), r.Size())
+		return
+	}
+	r.BlockSize = 512
+
+	for range 100 {
+		off := rand.Int64N(int64(len(data)))
+		buff := make([]byte, rand.IntN(2048))
+		n, err := r.ReadAt(buff, off)
+		if err != nil && !(err == io.EOF && off+int64(len(buff)) > int64(len(data))) {
+			t.Errorf("Got error reading %d bytes at %d: %s", len(buff), off, err)
+			return
+		} else if !bytes.Equal(buff[:n], data[off:off+int64(n)]) {
+			t.Errorf("Wrong data reading %d bytes at %d", len(buff), off)
+			return
+		}
+	}
+
+	for try := range 2 {
+		requests.Store(0)
+		pharInfo, err := phargo.NewReader(r, r.Size())
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		} else if len(pharInfo.Files) == 0 {
+			t.Error("No files")
+			return
+		} else if try == 1 && requests.Load() != 0 {
+			t.Errorf("Cached blocks should be used, got %d requests", requests.Load())
+			return
+		}
+	}
+}
+
+func TestNoRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("no range"))
+	}))
+	defer server.Close()
+
+	if _, err := New(server.URL); !errors.Is(err, ErrNoRange) {
+		t.Errorf("Should get ErrNoRange, got %v", err)
+		return
+	}
+}
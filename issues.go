@@ -0,0 +1,99 @@
+package phargo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidName is returned when entry name has invalid UTF-8, NUL or control characters
+// and [ReaderOptions.RejectInvalidNames] is set
+var ErrInvalidName = errors.New("invalid entry name")
+
+const (
+	IssueInvalidUTF8   IssueKind = iota + 1 // Entry name is not valid UTF-8
+	IssueNULByte                            // Entry name has NUL byte
+	IssueControlChar                        // Entry name has control character
+	IssueSizeMismatch                       // Declared lengths inconsistent with archive size
+	IssueTruncated                          // Archive ends before content or signature
+	IssueDuplicate                          // Entry name used by more than one entry
+	IssueBadCRC                             // Entry content not match manifest CRC
+	IssueTimestamp                          // Entry timestamp negative, before 1980 or in future
+	IssueTrailingBytes                      // Bytes between content and signature or end of file, like appended payloads
+)
+
+var issueName = map[IssueKind]string{
+	IssueInvalidUTF8:   "invalid_utf8",
+	IssueNULByte:       "nul_byte",
+	IssueControlChar:   "control_char",
+	IssueSizeMismatch:  "size_mismatch",
+	IssueTruncated:     "truncated",
+	IssueDuplicate:     "duplicate",
+	IssueBadCRC:        "bad_crc",
+	IssueTimestamp:     "timestamp",
+	IssueTrailingBytes: "trailing_bytes",
+}
+
+// IssueKind identify problem found while parsing archive
+type IssueKind int
+
+func (kind IssueKind) String() string {
+	if str, ok := issueName[kind]; ok {
+		return str
+	}
+	return "unknown"
+}
+
+func (kind IssueKind) MarshalText() (text []byte, err error) {
+	return []byte(kind.String()), nil
+}
+
+// Issue is a non fatal problem found while parsing archive
+type Issue struct {
+	Kind   IssueKind
+	Entry  string // Entry name, empty to archive issues
+	Detail string
+}
+
+func (issue Issue) String() string {
+	if issue.Entry == "" {
+		return fmt.Sprintf("%s: %s", issue.Kind, issue.Detail)
+	}
+	return fmt.Sprintf("%s: %q %s", issue.Kind, issue.Entry, issue.Detail)
+}
+
+// nameIssues return issues of entry name
+func nameIssues(name string) (issues []Issue) {
+	if !utf8.ValidString(name) {
+		issues = append(issues, Issue{Kind: IssueInvalidUTF8, Entry: name, Detail: "is not valid UTF-8"})
+	}
+	if index := strings.IndexByte(name, 0); index >= 0 {
+		issues = append(issues, Issue{Kind: IssueNULByte, Entry: name, Detail: fmt.Sprintf("has NUL byte at %d", index)})
+	}
+	if index := strings.IndexFunc(name, func(char rune) bool { return char != 0 && unicode.IsControl(char) }); index >= 0 {
+		issues = append(issues, Issue{Kind: IssueControlChar, Entry: name, Detail: fmt.Sprintf("has control character %q at %d", name[index], index)})
+	}
+	return issues
+}
+
+// Timestamps before this are reported by [timestampIssues], ZIP and FAT can't store them
+var minTimestamp = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Timestamps after now plus this are reported by [timestampIssues] as far in future
+const maxTimestampSkew = 24 * time.Hour
+
+// timestampIssues return issues of entry timestamp
+func timestampIssues(file *File, now time.Time) []Issue {
+	switch unix := file.Timestamp.Unix(); {
+	case int32(uint32(unix)) < 0:
+		return []Issue{{Kind: IssueTimestamp, Entry: file.Filename, Detail: fmt.Sprintf("has negative timestamp %d", int32(uint32(unix)))}}
+	case file.Timestamp.Before(minTimestamp):
+		return []Issue{{Kind: IssueTimestamp, Entry: file.Filename, Detail: fmt.Sprintf("has timestamp %s before 1980", file.Timestamp.UTC().Format(time.RFC3339))}}
+	case file.Timestamp.After(now.Add(maxTimestampSkew)):
+		return []Issue{{Kind: IssueTimestamp, Entry: file.Filename, Detail: fmt.Sprintf("has timestamp %s in future", file.Timestamp.UTC().Format(time.RFC3339))}}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package phargo
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNameIssues(t *testing.T) {
+	for name, kinds := range map[string][]IssueKind{
+		"src/index.php":    nil,
+		"ação/日本.php":      nil,
+		"bad\xff.php":      {IssueInvalidUTF8},
+		"nul\x00.php":      {IssueNULByte},
+		"tab\t.php":        {IssueControlChar},
+		"both\x00\x1b\xfe": {IssueInvalidUTF8, IssueNULByte, IssueControlChar},
+	} {
+		issues := nameIssues(name)
+		if len(issues) != len(kinds) {
+			t.Errorf("Expect %v issues to %q, got %v", kinds, name, issues)
+			return
+		}
+		for index, issue := range issues {
+			if issue.Kind != kinds[index] || issue.Entry != name {
+				t.Errorf("Expect %v issues to %q, got %v", kinds, name, issues)
+				return
+			}
+		}
+	}
+
+	data := buildPhar(testEntry{name: "evil\x00.php.txt", data: []byte("EVIL")})
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(file.Issues) != 1 || file.Issues[0].Kind != IssueNULByte {
+		t.Errorf("Should report NUL issue, got %v", file.Issues)
+		return
+	}
+
+	if _, err = NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{RejectInvalidNames: true}); !errors.Is(err, ErrInvalidName) {
+		t.Errorf("Should get ErrInvalidName, got %v", err)
+		return
+	}
+}
+
+func TestTimestampIssues(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	for unix, expected := range map[int64]bool{
+		int64(uint32(1 << 31)):      true,  // Negative as int32
+		0:                           true,  // Before 1980
+		now.Unix():                  false, // Valid
+		now.Add(time.Hour).Unix():   false, // Clock skew
+		now.AddDate(1, 0, 0).Unix(): true,  // Far future
+	} {
+		issues := timestampIssues(&File{Filename: "a.txt", Timestamp: time.Unix(unix, 0)}, now)
+		if (len(issues) > 0) != expected {
+			t.Errorf("Timestamp %d should report %v, got %v", unix, expected, issues)
+			return
+		}
+	}
+}
+
+func TestTrailingBytes(t *testing.T) {
+	data := buildPhar(testEntry{name: "a.txt", data: []byte("AAAA")})
+	for name, data := range map[string][]byte{
+		"unsigned": append(bytes.Clone(data), "PAYLOAD"...),
+		"signed":   signPhar(append(bytes.Clone(data), "PAYLOAD"...)),
+	} {
+		file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Errorf("Got error with %s: %s", name, err)
+			return
+		} else if len(file.Issues) != 1 || file.Issues[0].Kind != IssueTrailingBytes || !strings.HasPrefix(file.Issues[0].Detail, "7 bytes") {
+			t.Errorf("Should report 7 trailing bytes with %s, got %v", name, file.Issues)
+			return
+		}
+	}
+
+	data = signPhar(data)
+	if file, err := NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(file.Issues) != 0 {
+		t.Errorf("Should not report issues, got %v", file.Issues)
+		return
+	}
+}
@@ -3,12 +3,12 @@ package phargo
 import (
 	"bytes"
 	"compress/bzip2"
-	"compress/gzip"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"io/fs"
+	"iter"
 	"path"
 	"strings"
 	"time"
@@ -18,14 +18,14 @@ const (
 	ManifestBitmapDeflate = 0x00001000
 	ManifestBitmapBzip2   = 0x00002000
 
-	EntryPermMask      = 0x000001FF
-	EntryPermMask_usr  = 0x000001C0
-	EntryPermShift_usr = 6
-	EntryPermMask_grp  = 0x00000038
-	EntryPermShift_grp = 3
-	EntryPermMask_oth  = 0x00000007
-	EntryPermDef_file  = 0x000001B6
-	EntryPermDef_dir   = 0x000001FF
+	EntryPermMask     = 0x000001FF
+	EntryPermMaskUsr  = 0x000001C0
+	EntryPermShiftUsr = 6
+	EntryPermMaskGrp  = 0x00000038
+	EntryPermShiftGrp = 3
+	EntryPermMaskOth  = 0x00000007
+	EntryPermDefFile  = 0x000001B6
+	EntryPermDefDir   = 0x000001FF
 
 	CompressionMask      = 0xF000
 	EntryCompressedNone  = 0x00000000
@@ -33,18 +33,38 @@ const (
 	EntryCompressedBzip2 = 0x00002000
 )
 
+// Old permission names, kept to old code until next release
+const (
+	// Deprecated: use EntryPermMaskUsr
+	EntryPermMask_usr = EntryPermMaskUsr
+	// Deprecated: use EntryPermShiftUsr
+	EntryPermShift_usr = EntryPermShiftUsr
+	// Deprecated: use EntryPermMaskGrp
+	EntryPermMask_grp = EntryPermMaskGrp
+	// Deprecated: use EntryPermShiftGrp
+	EntryPermShift_grp = EntryPermShiftGrp
+	// Deprecated: use EntryPermMaskOth
+	EntryPermMask_oth = EntryPermMaskOth
+	// Deprecated: use EntryPermDefFile
+	EntryPermDef_file = EntryPermDefFile
+	// Deprecated: use EntryPermDefDir
+	EntryPermDef_dir = EntryPermDefDir
+)
+
 type File struct {
 	Filename         string
 	Timestamp        time.Time
 	Size             int64
-	Flags            uint32
+	Flags            EntryFlags
 	SizeUncompressed int64
 	SizeCompressed   int64
 	CRC              uint32
 	MetaSerialized   []byte
 
+	dir                 bool // Manifest name ends with "/", like PHP Phar::addEmptyDir entries
 	metadataOpen        io.ReaderAt
 	dataOffset, dataLen int64
+	cache               *entryCache
 }
 
 type fileInfo struct {
@@ -57,18 +77,12 @@ func (fs fileInfo) ModTime() time.Time { return fs.V.Timestamp }
 func (fs fileInfo) IsDir() bool        { return fs.Mode().IsDir() }
 func (fs fileInfo) Sys() any           { return fs.V }
 func (fss fileInfo) Mode() fs.FileMode {
-	PermMask := fss.V.Flags & EntryPermMask
-	UserPerm := PermMask & EntryPermMask_usr >> EntryPermShift_usr
-	GroupPerm := PermMask & EntryPermMask_grp >> EntryPermShift_grp
-	OtherPerm := PermMask & EntryPermMask_oth
-	Perm := fs.FileMode(UserPerm | GroupPerm | OtherPerm)
+	// Permission bits use same layout of unix mode
+	Perm := fss.V.Flags.Perm()
 
 	// Check if file or dir
-	switch {
-	case fss.V.SizeUncompressed == 0 && fss.V.SizeCompressed == 0:
+	if fss.V.dir {
 		Perm |= fs.ModeDir
-	default:
-		Perm |= fs.ModeType
 	}
 	return Perm
 }
@@ -78,112 +92,220 @@ func (file *File) FileInfo() fs.FileInfo {
 	return &fileInfo{file}
 }
 
-// Return file reader with descompression if compressed
+// Return file reader with decompression if compressed
 func (file File) Open() (io.ReadCloser, error) {
-	r := io.LimitReader(newReaderFromReaderAtOffset(file.metadataOpen, file.dataOffset), file.dataLen)
+	if file.cache != nil {
+		return file.cache.open(file)
+	}
+	return file.openData()
+}
+
+// ErrUncompressedSize is returned when compressed entry expand past its declared uncompressed size
+var ErrUncompressedSize = errors.New("entry bigger than uncompressed size")
+
+// openData return file reader from archive without cache
+func (file File) openData() (io.ReadCloser, error) {
+	return file.decompress(newSectionReader(file.metadataOpen, file.dataOffset, file.dataLen)), nil
+}
+
+// decompress return reader of entry content from stored data r
+func (file File) decompress(r io.Reader) io.ReadCloser {
 	switch {
-	case file.Flags&EntryCompressedGzip > 0:
-		return gzip.NewReader(r)
-	case file.Flags&EntryCompressedBzip2 > 0:
-		return io.NopCloser(bzip2.NewReader(r)), nil
+	case file.Flags.Compression() == EntryCompressedGzip:
+		// PHP stores gzip entries as raw deflate streams, without gzip header
+		return &sizeLimitReader{newFlateReader(r), file.SizeUncompressed}
+	case file.Flags.Compression() == EntryCompressedBzip2:
+		return &sizeLimitReader{io.NopCloser(bzip2.NewReader(r)), file.SizeUncompressed}
 	default:
-		return io.NopCloser(r), nil
+		return io.NopCloser(r)
+	}
+}
+
+// sizeLimitReader return [ErrUncompressedSize] when decompressor produce more than remaining bytes
+type sizeLimitReader struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (r *sizeLimitReader) Read(p []byte) (int, error) {
+	// Read one byte past limit to detect bigger streams
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if int64(n) > r.remaining {
+		n, r.remaining = int(r.remaining), 0
+		return n, ErrUncompressedSize
 	}
+	r.remaining -= int64(n)
+	return n, err
 }
 
 // Parse file entry manifest to struct
 //
 // PHP Docs: https://www.php.net/manual/en/phar.fileformat.manifestfile.php
 func ParseEntryManifest(r io.ReaderAt, offset int64) (*File, int64, error) {
-	buff := make([]byte, 28)
-	if n, err := r.ReadAt(buff[:4], offset); err != nil {
-		return nil, offset + int64(n), fmt.Errorf("cannot get filename size: %s", err)
+	return parseEntryManifest(r, offset, ReaderOptions{})
+}
+
+// readerSize is implemented by readers knowing their end offset, like [*bytes.Reader] and [*MmapFile]
+type readerSize interface {
+	Size() int64
+}
+
+// checkRange return [ErrBadManifest] if n bytes from offset pass r end, when r know its size
+func checkRange(r io.ReaderAt, name string, offset, n int64) error {
+	if sized, ok := r.(readerSize); ok && n > sized.Size()-offset {
+		return fmt.Errorf("%w: %s %d pass end of manifest at %d", ErrBadManifest, name, n, sized.Size())
 	}
-	filenameSize := binary.LittleEndian.Uint32(buff[:4])
-	buff = bytes.Join([][]byte{buff, make([]byte, filenameSize)}, []byte{})
-	if n, err := r.ReadAt(buff, offset); err != nil {
-		return nil, offset + int64(n), fmt.Errorf("cannot get meta size: %s", err)
+	return nil
+}
+
+// parseEntryManifest parse file entry enforcing options limits,
+// each length is checked before buffers are allocated
+func parseEntryManifest(r io.ReaderAt, offset int64, options ReaderOptions) (*File, int64, error) {
+	if offset < 0 {
+		return nil, offset, fmt.Errorf("%w: negative entry offset %d", ErrBadManifest, offset)
 	}
-	offset += int64(len(buff))
-	filenameSize += 4
-	name := path.Clean(string(buff[4:filenameSize]))
-	var eb struct {
-		SizeUncompressed uint32
-		Timestamp        uint32
-		SizeCompressed   uint32
-		CRC              uint32
-		Flags            uint32
-		MetaLength       uint32
-	}
-	binary.Read(bytes.NewReader(buff[filenameSize:]), binary.LittleEndian, &eb)
-	buff = buff[filenameSize+24:]
 
-	// Make buff to Meta
-	if eb.MetaLength > 0 {
-		buff = make([]byte, eb.MetaLength)
-		if n, err := r.ReadAt(buff, offset); err != nil {
-			return nil, offset + int64(n), fmt.Errorf("cannot get meta length: %s", err)
-		}
+	var sizeBuff [4]byte
+	if n, err := r.ReadAt(sizeBuff[:], offset); err != nil {
+		return nil, offset + int64(n), fmt.Errorf("cannot get filename size: %w", err)
 	}
+	offset += 4
 
+	// Filename followed by fixed 24 bytes of entry fields
+	filenameSize := int64(binary.LittleEndian.Uint32(sizeBuff[:]))
+	if filenameSize == 0 {
+		return nil, offset, fmt.Errorf("%w: zero length filename", ErrBadManifest)
+	} else if err := checkLimit("filename length", filenameSize, options.MaxFilenameLen); err != nil {
+		return nil, offset, err
+	} else if err := checkRange(r, "filename length", offset, filenameSize+24); err != nil {
+		return nil, offset, err
+	}
+	buff, err := readAt(r, offset, filenameSize+24)
+	if err != nil {
+		return nil, offset + int64(len(buff)), fmt.Errorf("cannot get meta size: %w", err)
+	}
+	offset += int64(len(buff))
+
+	fields, filename := buff[filenameSize:], string(buff[:filenameSize])
 	newManifest := &File{
-		Filename:         name,
-		SizeUncompressed: int64(eb.SizeUncompressed),
-		Timestamp:        time.Unix(int64(eb.Timestamp), 0),
-		SizeCompressed:   int64(eb.SizeCompressed),
-		CRC:              eb.CRC,
-		Flags:            eb.Flags,
-		MetaSerialized:   buff[:eb.MetaLength],
+		Filename:         path.Clean(filename),
+		SizeUncompressed: int64(binary.LittleEndian.Uint32(fields[0:4])),
+		Timestamp:        time.Unix(int64(binary.LittleEndian.Uint32(fields[4:8])), 0),
+		SizeCompressed:   int64(binary.LittleEndian.Uint32(fields[8:12])),
+		CRC:              binary.LittleEndian.Uint32(fields[12:16]),
+		Flags:            EntryFlags(binary.LittleEndian.Uint32(fields[16:20])),
+		MetaSerialized:   []byte{},
+		dir:              strings.HasSuffix(filename, "/"),
 		metadataOpen:     r,
 	}
 
+	// Make buff to Meta
+	if metaLength := binary.LittleEndian.Uint32(fields[20:24]); metaLength > 0 {
+		if err := checkLimit("entry metadata length", int64(metaLength), options.MaxMetadataLen); err != nil {
+			return nil, offset, err
+		} else if err := checkRange(r, "entry metadata length", offset, int64(metaLength)); err != nil {
+			return nil, offset, err
+		}
+		if newManifest.MetaSerialized, err = readAt(r, offset, int64(metaLength)); err != nil {
+			return nil, offset + int64(len(newManifest.MetaSerialized)), fmt.Errorf("cannot get meta length: %w", err)
+		}
+		offset += int64(metaLength)
+	}
+
 	// Append read file size to open
 	newManifest.dataLen = newManifest.SizeUncompressed
 	if newManifest.Flags&CompressionMask > 0 {
 		newManifest.dataLen = newManifest.SizeCompressed
+	} else if newManifest.SizeCompressed == 0 {
+		// Some builders write zero compressed size to stored entries, spec keep both sizes equal
+		newManifest.SizeCompressed = newManifest.SizeUncompressed
 	}
 
-	return newManifest, offset + int64(len(buff)), nil
+	return newManifest, offset, nil
 }
 
 type Manifest struct {
 	Length        uint32
 	EntitiesCount uint32
 	Version       string
-	Flags         uint32
+	Flags         GlobalFlags
 	Alias         []byte
 	AliasLength   uint32
 	Metadata      []byte
 	IsSigned      bool
+
+	offset  int64         // Manifest start offset in file
+	raw     *memReaderAt  // Manifest bytes
+	options ReaderOptions // Limits to parse entries
 }
 
-// Parse phar menifest
+// ContentOffset return offset where files content starts, after manifest
+func (manifest *Manifest) ContentOffset() int64 {
+	return manifest.offset + 4 + int64(manifest.Length)
+}
+
+// Parse phar manifest
 //
 // PHP Docs: https://www.php.net/manual/en/phar.fileformat.phar.php
 func ParseManifest(r io.ReaderAt) (*Manifest, int64, error) {
-	offset, err := getOffset(r, 200, "__HALT_COMPILER(); ?>")
+	return parseManifest(r, -1, ReaderOptions{})
+}
+
+// parseManifest parse phar manifest enforcing options limits,
+// and checking manifest length against archive size if size is not negative
+func parseManifest(r io.ReaderAt, size int64, options ReaderOptions) (*Manifest, int64, error) {
+	offset, err := getOffset(r, stubScanChunkSize, haltCompiler)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	fistParams := make([]byte, 18)
-	if n, err := r.ReadAt(fistParams, offset); err != nil {
-		return nil, offset + int64(n), fmt.Errorf("cannot get initials params: %s", err)
+	var lengthBuff [4]byte
+	if n, err := r.ReadAt(lengthBuff[:], offset); err != nil {
+		return nil, offset + int64(n), fmt.Errorf("cannot get manifest length: %w", err)
+	}
+
+	// Read whole manifest in one operation and parse it from memory
+	manifestLength := int64(binary.LittleEndian.Uint32(lengthBuff[:]))
+	if err := checkLimit("manifest length", manifestLength, options.MaxManifestLen); err != nil {
+		return nil, offset, err
+	} else if size >= 0 && offset+4+manifestLength > size {
+		return nil, offset, &TruncatedError{Section: "manifest", Expected: offset + 4 + manifestLength, Available: size}
+	}
+	block, err := readAt(r, offset+4, manifestLength)
+	if err != nil {
+		return nil, offset + 4 + int64(len(block)), fmt.Errorf("cannot read manifest: %w", err)
+	}
+	raw := &memReaderAt{data: block, base: offset + 4}
+	r = raw
+
+	fistParams := make([]byte, 14)
+	if n, err := r.ReadAt(fistParams, offset+4); err != nil {
+		return nil, offset + 4 + int64(n), fmt.Errorf("%w: cannot get initials params: %w", ErrBadManifest, err)
 	}
 	offset += 18
 
 	newManifest := &Manifest{
-		Length:        binary.LittleEndian.Uint32(fistParams[:4]),
-		EntitiesCount: binary.LittleEndian.Uint32(fistParams[4:8]),
-		Version:       fmt.Sprintf("%d.%d.%d", (binary.LittleEndian.Uint16(fistParams[8:10])<<12)>>12, ((binary.LittleEndian.Uint16(fistParams[8:10])>>4)<<12)>>12, ((binary.LittleEndian.Uint16(fistParams[8:10])>>8)<<12)>>12),
-		Flags:         binary.LittleEndian.Uint32(fistParams[10:14]),
-		AliasLength:   binary.LittleEndian.Uint32(fistParams[14:]),
+		options:       options,
+		raw:           raw,
+		offset:        offset - 18,
+		Length:        binary.LittleEndian.Uint32(lengthBuff[:]),
+		EntitiesCount: binary.LittleEndian.Uint32(fistParams[:4]),
+		Version:       fmt.Sprintf("%d.%d.%d", (binary.LittleEndian.Uint16(fistParams[4:6])<<12)>>12, ((binary.LittleEndian.Uint16(fistParams[4:6])>>4)<<12)>>12, ((binary.LittleEndian.Uint16(fistParams[4:6])>>8)<<12)>>12),
+		Flags:         GlobalFlags(binary.LittleEndian.Uint32(fistParams[6:10])),
+		AliasLength:   binary.LittleEndian.Uint32(fistParams[10:]),
+	}
+	newManifest.IsSigned = newManifest.Flags&GlobalSigned != 0
+	if err := checkLimit("entries count", int64(newManifest.EntitiesCount), options.MaxEntries); err != nil {
+		return nil, offset, err
+	} else if err := checkLimit("alias length", int64(newManifest.AliasLength), options.MaxAliasLen); err != nil {
+		return nil, offset, err
 	}
-	newManifest.IsSigned = newManifest.Flags&0x10000 > 0
 
-	newManifest.Alias = make([]byte, newManifest.AliasLength)
-	if n, err := r.ReadAt(newManifest.Alias, offset); err != nil {
-		return nil, offset + int64(n), err
+	if newManifest.Alias, err = readAt(r, offset, int64(newManifest.AliasLength)); err != nil {
+		return nil, offset + int64(len(newManifest.Alias)), err
 	}
 	offset += int64(newManifest.AliasLength)
 
@@ -194,50 +316,204 @@ func ParseManifest(r io.ReaderAt) (*Manifest, int64, error) {
 	offset += 4
 
 	MetaLength := binary.LittleEndian.Uint32(metaLen)
-	if MetaLength > 0 {
-		newManifest.Metadata = make([]byte, MetaLength)
-		if n, err := r.ReadAt(newManifest.Metadata, offset); err != nil {
-			return nil, offset + int64(n), err
+	if err := checkLimit("metadata length", int64(MetaLength), options.MaxMetadataLen); err != nil {
+		return nil, offset, err
+	} else if MetaLength > 0 {
+		if newManifest.Metadata, err = readAt(r, offset, int64(MetaLength)); err != nil {
+			return nil, offset + int64(len(newManifest.Metadata)), err
 		}
 		offset += int64(MetaLength)
 	}
 	return newManifest, offset, nil
 }
 
-func getOffset(f io.ReaderAt, bufSize int64, haltCompiler string) (int64, error) {
-	currentPossion, buffer, before := int64(0), make([]byte, bufSize), make([]byte, bufSize)
-	for {
-		n, err := f.ReadAt(buffer, currentPossion)
-		if err != nil && err != io.EOF {
-			return 0, errors.New("can't find haltCompiler: " + err.Error())
+// Entries return iterator parsing file entries on demand, starting at entries offset returned by [ParseManifest].
+//
+// Files are yielded in manifest order with content offset set, ready to [File.Open],
+// iteration stops on first error.
+func (manifest *Manifest) Entries(r io.ReaderAt, offset int64) iter.Seq2[*File, error] {
+	return func(yield func(*File, error) bool) {
+		dataOffset := manifest.ContentOffset()
+		entriesReader := r
+		if manifest.raw != nil {
+			entriesReader = manifest.raw
 		}
 
-		search := append(before, buffer...)
-		index := strings.Index(string(search), haltCompiler)
-
-		if index >= 0 {
-			offset := currentPossion + int64(index) - bufSize + int64(len(haltCompiler))
-			if index+len(haltCompiler) >= len(search) {
-				return 0, errors.New("unexpected end of file")
+		for range manifest.EntitiesCount {
+			file, newOffset, err := parseEntryManifest(entriesReader, offset, manifest.options)
+			if err != nil {
+				yield(nil, fmt.Errorf("cannot get file entry: %w", err))
+				return
+			}
+			offset = newOffset
+			file.metadataOpen = r
+			file.dataOffset = dataOffset
+			dataOffset += file.dataLen
+			if !yield(file, nil) {
+				return
 			}
+		}
+	}
+}
+
+// Entries parse phar manifest and return iterator over file entries,
+// reading each entry only when requested, to list huge archives with low memory.
+func Entries(r io.ReaderAt) iter.Seq2[*File, error] {
+	manifest, offset, err := ParseManifest(r)
+	if err != nil {
+		return func(yield func(*File, error) bool) {
+			yield(nil, fmt.Errorf("cannot parse manifest: %w", err))
+		}
+	}
+	return manifest.Entries(r, offset)
+}
+
+// Walk parse phar manifest with size checks and call fn to each entry in manifest order,
+// entries are not retained, so one pass scanners use memory of one entry at time.
+//
+// Walk stops at first error of parser or fn, fn returning [fs.SkipAll] stops without error.
+// Signature and CRC are not checked.
+func Walk(r io.ReaderAt, size int64, fn func(*File) error) error {
+	manifest, offset, err := parseManifest(r, size, ReaderOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot parse manifest: %w", err)
+	}
+	for file, err := range manifest.Entries(r, offset) {
+		if err != nil {
+			return err
+		}
+		if err := fn(file); err == fs.SkipAll {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-			//optional \r\n or \n
-			var nextChar = search[index+len(haltCompiler)]
-			var nextNextChar = search[index+len(haltCompiler)+1]
-			if nextChar == '\r' && nextNextChar == '\n' {
-				offset += 2
+// Stub scan window size
+const stubScanChunkSize = 8 * 1024
+
+// haltCompiler is the marker ending phar stub, followed by tail parsed by [haltCompilerEnd]
+var haltCompiler = []byte("__HALT_COMPILER")
+
+// ErrNoHaltCompiler is returned when stub has no __HALT_COMPILER, so file is not phar archive
+var ErrNoHaltCompiler = errors.New("can't find haltCompiler")
+
+// Tail bytes after haltCompiler read to find archive start
+const haltCompilerTailSize = 64
+
+// stubLexWindow is bytes after first raw haltCompiler marker tokenized by [stubHaltCompiler],
+// so marker in string or comment before real one is skipped without reading whole file
+const stubLexWindow = 64 * 1024
+
+// getOffset return offset where archive start. File is scanned in chunkSize windows for first
+// haltCompiler marker with valid tail, ignoring case, then stub until marker and [stubLexWindow]
+// after it is tokenized by [stubHaltCompiler] to skip markers in strings or comments.
+// Stubs it can't decide, like binary launchers, use first raw marker.
+// Windows overlap so marker across chunks edge is found.
+func getOffset(r io.ReaderAt, chunkSize int, haltCompiler []byte) (int64, error) {
+	marker, end, err := rawHaltCompiler(r, chunkSize, haltCompiler)
+	if err != nil {
+		return 0, err
+	} else if offset, ok := stubHaltCompiler(r, marker+int64(len(haltCompiler))+stubLexWindow); ok {
+		return offset, nil
+	}
+	return end, nil
+}
+
+// rawHaltCompiler return offset of first haltCompiler marker with valid tail, and archive start after it
+func rawHaltCompiler(r io.ReaderAt, chunkSize int, haltCompiler []byte) (int64, int64, error) {
+	overlap := len(haltCompiler) - 1
+	buff := make([]byte, max(chunkSize, len(haltCompiler))+overlap)
+
+	var base int64 // Offset of buff[0] in r
+	keep := 0      // Bytes from previous window in buff head
+	for {
+		n, err := r.ReadAt(buff[keep:], base+int64(keep))
+		if err != nil && err != io.EOF {
+			return 0, 0, fmt.Errorf("%w: %w", ErrNoHaltCompiler, err)
+		}
+
+		// Marker without valid tail, like in comments, is skipped
+		window := buff[:keep+n]
+		for start := 0; start < len(window); {
+			index := indexFold(window[start:], haltCompiler)
+			if index < 0 {
+				break
 			}
-			if nextChar == '\n' {
-				offset++
+			start += index + 1
+			marker := base + int64(start-1)
+			if offset, ok := haltCompilerEnd(r, marker+int64(len(haltCompiler))); ok {
+				return marker, offset, nil
 			}
+		}
+		if err == io.EOF || n == 0 {
+			return 0, 0, fmt.Errorf("%w: unexpected end of file", ErrNoHaltCompiler)
+		}
+
+		keep = min(overlap, len(window))
+		copy(buff, window[len(window)-keep:])
+		base += int64(len(window) - keep)
+	}
+}
 
-			return offset, nil
+// indexFold is [bytes.Index] ignoring ASCII case, like PHP keywords, marker must start with non letter
+func indexFold(s, marker []byte) int {
+	for start := 0; ; {
+		index := bytes.IndexByte(s[start:], marker[0])
+		if index < 0 {
+			return -1
 		}
+		start += index
+		if len(s)-start < len(marker) {
+			return -1
+		} else if bytes.EqualFold(s[start:start+len(marker)], marker) {
+			return start
+		}
+		start++
+	}
+}
+
+// haltCompilerEnd parse tail after haltCompiler like PHP, accepting "();", "(); ?>" or "() ?>"
+// with any whitespace between tokens and optional newline after "?>", returning offset where archive start
+func haltCompilerEnd(r io.ReaderAt, offset int64) (int64, bool) {
+	var buff [haltCompilerTailSize]byte
+	n, _ := r.ReadAt(buff[:], offset)
+	tail, pos := buff[:n], 0
+
+	skipSpace := func() {
+		for pos < len(tail) && (tail[pos] == ' ' || tail[pos] == '\t' || tail[pos] == '\r' || tail[pos] == '\n') {
+			pos++
+		}
+	}
+	token := func(token string) bool {
+		if bytes.HasPrefix(tail[pos:], []byte(token)) {
+			pos += len(token)
+			return true
+		}
+		return false
+	}
 
-		currentPossion += int64(n)
-		copy(before, buffer)
-		if err == io.EOF {
-			return currentPossion + int64(index) - bufSize + int64(len(haltCompiler)), nil
+	if skipSpace(); !token("(") {
+		return 0, false
+	} else if skipSpace(); !token(")") {
+		return 0, false
+	}
+	skipSpace()
+	if token(";") {
+		// Archive start just after ";" when stub not close php tag
+		end := pos
+		if skipSpace(); !token("?>") {
+			return offset + int64(end), true
 		}
+	} else if !token("?>") {
+		return 0, false
+	}
+
+	//optional \r\n or \n
+	if !token("\r\n") {
+		token("\n")
 	}
+	return offset + int64(pos), true
 }
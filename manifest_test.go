@@ -0,0 +1,331 @@
+package phargo
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	osFile, err := os.Open("./testdata/simple.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+
+	var names []string
+	for file, err := range Entries(osFile) {
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		}
+		names = append(names, file.Filename)
+
+		f, err := file.Open()
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		}
+		data, _ := io.ReadAll(f)
+		if file.Filename == "index.php" && string(data) != "ZXCV" {
+			t.Error("Wrong index.php content")
+			return
+		}
+	}
+
+	if len(names) != 2 || names[0] != "1.txt" || names[1] != "index.php" {
+		t.Errorf("Wrong entries: %v", names)
+		return
+	}
+}
+
+func TestGetOffset(t *testing.T) {
+	for _, marker := range []string{"__HALT_COMPILER(); ?>", "__HALT_COMPILER();", "__HALT_COMPILER ( ) ;\t?>", "__HALT_COMPILER()?>"} {
+		for _, suffix := range []string{"", "\n", "\r\n"} {
+			if !strings.HasSuffix(marker, "?>") && suffix != "" {
+				continue // Newline after ";" is archive content
+			}
+			for prefixLen := range 40 {
+				data := append(bytes.Repeat([]byte{0}, prefixLen), "/* __HALT_COMPILER */"+marker...)
+				data = append(data, suffix...)
+				data = append(data, "DATA"...)
+				expected := int64(prefixLen + len("/* __HALT_COMPILER */"+marker) + len(suffix))
+
+				for chunkSize := 1; chunkSize <= 48; chunkSize++ {
+					offset, err := getOffset(bytes.NewReader(data), chunkSize, haltCompiler)
+					if err != nil {
+						t.Errorf("Got error with %q, prefix %d and chunk %d: %s", marker, prefixLen, chunkSize, err)
+						return
+					} else if offset != expected {
+						t.Errorf("Wrong offset with %q, prefix %d and chunk %d: expect %d, got %d", marker, prefixLen, chunkSize, expected, offset)
+						return
+					}
+				}
+			}
+		}
+	}
+
+	if _, err := getOffset(bytes.NewReader([]byte("<?php echo 1;")), 4, haltCompiler); !errors.Is(err, ErrNoHaltCompiler) {
+		t.Errorf("Should get ErrNoHaltCompiler without haltCompiler, got %v", err)
+		return
+	}
+}
+
+func BenchmarkParseEntryManifest(b *testing.B) {
+	data := buildPhar(testEntry{name: "src/vendor/autoload.php", data: []byte("<?php")})
+	r := bytes.NewReader(data)
+	manifest, offset, err := ParseManifest(r)
+	if err != nil {
+		b.Fatal(err)
+	} else if manifest.EntitiesCount != 1 {
+		b.Fatal("Not 1 entry")
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, _, err := ParseEntryManifest(r, offset); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// countReaderAt count ReadAt calls
+type countReaderAt struct {
+	io.ReaderAt
+	calls int
+}
+
+func (r *countReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.calls++
+	return r.ReaderAt.ReadAt(p, off)
+}
+
+func TestEntriesReadCalls(t *testing.T) {
+	osFile, err := os.Open("./testdata/phpDocumentor.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+
+	r := &countReaderAt{ReaderAt: osFile}
+	manifest, offset, err := ParseManifest(r)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	stubCalls := r.calls
+
+	count := 0
+	for _, err := range manifest.Entries(r, offset) {
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		}
+		count++
+	}
+
+	if count != int(manifest.EntitiesCount) {
+		t.Errorf("Expect %d entries, got %d", manifest.EntitiesCount, count)
+		return
+	} else if r.calls != stubCalls {
+		t.Errorf("Entries should parse from memory, got %d ReadAt calls", r.calls-stubCalls)
+		return
+	}
+}
+
+func TestHugeDeclaredLengths(t *testing.T) {
+	valid := buildPhar(testEntry{name: "a.txt", data: []byte("AAAA")})
+	offset, err := getOffset(bytes.NewReader(valid), stubScanChunkSize, haltCompiler)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	manifestLength := slices.Clone(valid)
+	binary.LittleEndian.PutUint32(manifestLength[offset:], 0xFFFFFFFF)
+	filenameLength := slices.Clone(valid)
+	binary.LittleEndian.PutUint32(filenameLength[offset+18+4:], 0xFFFFFFF0)
+
+	for name, data := range map[string][]byte{"manifest": manifestLength, "filename": filenameLength} {
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		_, err := NewReader(bytes.NewReader(data), int64(len(data)))
+		runtime.ReadMemStats(&after)
+
+		if err == nil {
+			t.Errorf("Should get error with huge %s length", name)
+			return
+		} else if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 16<<20 {
+			t.Errorf("Huge %s length allocated %d bytes", name, allocated)
+			return
+		}
+	}
+}
+
+func FuzzParseEntryManifest(f *testing.F) {
+	data := buildPhar(testEntry{name: "a.txt", data: []byte("AAAA")})
+	stubLen := len("<?php __HALT_COMPILER(); ?>\r\n")
+	f.Add(data[stubLen+4+18:])
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		file, next, err := ParseEntryManifest(bytes.NewReader(data), 0)
+		if err != nil {
+			return
+		} else if next > int64(len(data)) {
+			t.Errorf("Entry end %d pass input size %d", next, len(data))
+		} else if file.Filename == "" {
+			t.Error("Entry without name")
+		}
+	})
+}
+
+func TestBinaryStub(t *testing.T) {
+	// Big stub with NUL bytes and marker fragments near window edges
+	stub := bytes.Repeat([]byte{0, 0xFF, '_', 'H'}, stubScanChunkSize)
+	for index := stubScanChunkSize - 8; index < len(stub)-len(haltCompiler); index += stubScanChunkSize {
+		copy(stub[index:], haltCompiler[:len(haltCompiler)-1])
+	}
+	data := append(stub[:len(stub)-10], buildPhar(testEntry{name: "a.txt", data: []byte("AAAA")})...)
+
+	pharInfo, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(pharInfo.Files) != 1 || pharInfo.Files[0].Filename != "a.txt" {
+		t.Errorf("Should get a.txt, got %v", pharInfo.Files)
+		return
+	}
+}
+
+func TestRegularFileMode(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "a.txt", data: []byte("AAAA"), flags: 0640},
+		testEntry{name: "b.txt", data: []byte("BBBB"), crc: 1},
+	)
+
+	// Regular files with type bits were taken as folders, and their CRC was not checked
+	if _, err := NewReader(bytes.NewReader(data), int64(len(data))); !errors.Is(err, ErrBadCRC) {
+		t.Errorf("Should get ErrBadCRC, got %v", err)
+		return
+	}
+	pharInfo, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{SkipCRC: true})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if mode := pharInfo.Files[0].FileInfo().Mode(); !mode.IsRegular() || mode != 0640 {
+		t.Errorf("Wrong a.txt mode %s", mode)
+		return
+	}
+}
+
+func TestUncompressedSize(t *testing.T) {
+	var compressed bytes.Buffer
+	w, _ := flate.NewWriter(&compressed, flate.BestCompression)
+	w.Write(bytes.Repeat([]byte("A"), 1<<20))
+	w.Close()
+
+	file := &File{
+		Filename:         "bomb.txt",
+		Flags:            EntryCompressedGzip,
+		SizeUncompressed: 1024,
+		SizeCompressed:   int64(compressed.Len()),
+		metadataOpen:     bytes.NewReader(compressed.Bytes()),
+		dataLen:          int64(compressed.Len()),
+	}
+	r, err := file.Open()
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	defer r.Close()
+
+	if data, err := io.ReadAll(r); !errors.Is(err, ErrUncompressedSize) {
+		t.Errorf("Should get ErrUncompressedSize, got %v", err)
+		return
+	} else if len(data) != 1024 {
+		t.Errorf("Should read declared 1024 bytes, got %d", len(data))
+		return
+	}
+}
+
+func TestStoredZeroCompressedSize(t *testing.T) {
+	// simple.phar with zero compressed size in entries and signature updated
+	osFile, err := os.Open("./testdata/stored_zero_compressed.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+
+	file, err := NewReaderFromFile(osFile)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	for name, content := range map[string]string{"1.txt": "ASDF", "index.php": "ZXCV"} {
+		entry, err := fs.Stat(file, name)
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		} else if entry.Size() != 4 || entry.Sys().(*File).SizeCompressed != 4 {
+			t.Errorf("%s sizes not normalized: %d, %d", name, entry.Size(), entry.Sys().(*File).SizeCompressed)
+			return
+		}
+		if data, err := fs.ReadFile(file, name); err != nil || string(data) != content {
+			t.Errorf("Wrong %s content: %q, %v", name, data, err)
+			return
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "a.txt", data: []byte("AAAA")},
+		testEntry{name: "b.txt", data: []byte("BBBB")},
+		testEntry{name: "c.txt", data: []byte("CCCC")},
+	)
+
+	var names []string
+	err := Walk(bytes.NewReader(data), int64(len(data)), func(file *File) error {
+		names = append(names, file.Filename)
+		if file.Filename == "b.txt" {
+			f, err := file.Open()
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			if content, err := io.ReadAll(f); err != nil || string(content) != "BBBB" {
+				t.Errorf("Wrong b.txt content: %q, %v", content, err)
+			}
+			return fs.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if !slices.Equal(names, []string{"a.txt", "b.txt"}) {
+		t.Errorf("Wrong entries walked: %v", names)
+		return
+	}
+
+	errStop := errors.New("stop")
+	if err := Walk(bytes.NewReader(data), int64(len(data)), func(*File) error { return errStop }); err != errStop {
+		t.Errorf("Should get fn error, got %v", err)
+		return
+	}
+	if err := Walk(bytes.NewReader(data[:40]), 40, func(*File) error { return nil }); err == nil {
+		t.Error("Truncated archive should fail")
+		return
+	}
+}
@@ -0,0 +1,19 @@
+package phargo
+
+import "github.com/Sirherobrine23/phargo/phpserialize"
+
+// Unserialize decode archive metadata with [phpserialize.Unmarshal], nil without metadata
+func (manifest *Manifest) Unserialize() (any, error) {
+	if len(manifest.Metadata) == 0 {
+		return nil, nil
+	}
+	return phpserialize.Unmarshal(manifest.Metadata)
+}
+
+// Unserialize decode entry metadata with [phpserialize.Unmarshal], nil without metadata
+func (file *File) Unserialize() (any, error) {
+	if len(file.MetaSerialized) == 0 {
+		return nil, nil
+	}
+	return phpserialize.Unmarshal(file.MetaSerialized)
+}
@@ -0,0 +1,67 @@
+package phargo
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"testing"
+)
+
+func TestUnserialize(t *testing.T) {
+	osFile, err := os.Open("./testdata/metadata_dir_sha256.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+	stat, _ := osFile.Stat()
+
+	phar, err := NewReader(osFile, stat.Size())
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	if meta, err := phar.Manifest.Unserialize(); err != nil || meta != nil {
+		t.Errorf("Archive without metadata: %v, %v", meta, err)
+		return
+	}
+
+	expected := map[string]map[string]any{
+		"FILE":       {"v": "x"},
+		"DIR2/FILE1": {"z": "cc"},
+	}
+	for _, file := range phar.Files {
+		meta, err := file.Unserialize()
+		if err != nil {
+			t.Errorf("%s: %s", file.Filename, err)
+			return
+		}
+		want, ok := expected[file.Filename]
+		if !ok {
+			if meta != nil {
+				t.Errorf("%s: unexpected metadata %v", file.Filename, meta)
+			}
+			continue
+		}
+		if got, _ := meta.(map[string]any); !maps.Equal(got, want) {
+			t.Errorf("%s: wrong metadata %#v", file.Filename, meta)
+			return
+		}
+	}
+
+	simple, err := os.ReadFile("./testdata/simple.phar")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	phar, err = NewReader(bytes.NewReader(simple), int64(len(simple)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	if meta, err := phar.Manifest.Unserialize(); err != nil || !maps.Equal(meta.(map[string]any), map[string]any{"a": int64(123)}) {
+		t.Errorf("Wrong archive metadata: %#v, %v", meta, err)
+		return
+	}
+}
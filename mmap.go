@@ -0,0 +1,68 @@
+package phargo
+
+import (
+	"io"
+	"os"
+)
+
+// MmapFile is archive file memory mapped to fast random access,
+// on systems without mmap support reads go to file.
+type MmapFile struct {
+	file *os.File
+	data []byte
+	size int64
+}
+
+// OpenMmap open and memory map file to read
+func OpenMmap(name string) (*MmapFile, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	mmapFile := &MmapFile{file: file, size: stat.Size()}
+	if err := mmapFile.mmap(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return mmapFile, nil
+}
+
+// Size return file size
+func (file *MmapFile) Size() int64 { return file.size }
+
+// ReadAt implements [io.ReaderAt]
+func (file *MmapFile) ReadAt(p []byte, off int64) (int, error) {
+	if file.data == nil {
+		return file.file.ReadAt(p, off)
+	} else if off < 0 {
+		return 0, os.ErrInvalid
+	} else if off >= file.size {
+		return 0, io.EOF
+	}
+
+	n := copy(p, file.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Close unmap and close file
+func (file *MmapFile) Close() error {
+	err := file.munmap()
+	if closeErr := file.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Parse phar file from memory mapped file, file must be open while [*Phar] is in use
+func NewReaderFromMmap(file *MmapFile) (*Phar, error) {
+	return NewReader(file, file.Size())
+}
@@ -0,0 +1,6 @@
+//go:build !unix
+
+package phargo
+
+func (file *MmapFile) mmap() error   { return nil }
+func (file *MmapFile) munmap() error { return nil }
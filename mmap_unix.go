@@ -0,0 +1,27 @@
+//go:build unix
+
+package phargo
+
+import "syscall"
+
+func (file *MmapFile) mmap() error {
+	if file.size == 0 || int64(int(file.size)) != file.size {
+		return nil // Empty or too large to map, read from file
+	}
+
+	data, err := syscall.Mmap(int(file.file.Fd()), 0, int(file.size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	file.data = data
+	return nil
+}
+
+func (file *MmapFile) munmap() error {
+	if file.data == nil {
+		return nil
+	}
+	data := file.data
+	file.data = nil
+	return syscall.Munmap(data)
+}
@@ -0,0 +1,133 @@
+// Package multireaderat stitch [io.ReaderAt] segments, like parts of split archive
+// "app.phar.001", "app.phar.002", into one [io.ReaderAt], to parse and verify
+// archives without concatenating parts on disk.
+package multireaderat
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+)
+
+// ErrNoParts is returned when no split part is found
+var ErrNoParts = errors.New("no split parts found")
+
+// Part is one segment of logical file
+type Part struct {
+	R    io.ReaderAt
+	Size int64
+}
+
+// ReaderAt read parts as one file, part n start after all bytes of previous parts.
+// It is safe for concurrent use if parts readers are.
+type ReaderAt struct {
+	parts  []Part
+	starts []int64 // Offset of each part in logical file
+	size   int64
+}
+
+// New return [ReaderAt] of parts in order
+func New(parts ...Part) *ReaderAt {
+	r := &ReaderAt{parts: parts, starts: make([]int64, len(parts))}
+	for index, part := range parts {
+		r.starts[index] = r.size
+		r.size += part.Size
+	}
+	return r
+}
+
+// Size return sum of parts sizes
+func (r *ReaderAt) Size() int64 { return r.size }
+
+func (r *ReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("multireaderat: negative offset %d", off)
+	} else if off >= r.size {
+		return 0, io.EOF
+	}
+
+	// Last part starting at or before off
+	index, found := slices.BinarySearch(r.starts, off)
+	if !found {
+		index--
+	}
+
+	var n int
+	for ; n < len(p) && index < len(r.parts); index++ {
+		part, partOff := r.parts[index], off+int64(n)-r.starts[index]
+		if partOff >= part.Size {
+			continue // Empty part
+		}
+		want := min(int64(len(p)-n), part.Size-partOff)
+		read, err := part.R.ReadAt(p[n:n+int(want)], partOff)
+		n += read
+		if err != nil && !(err == io.EOF && int64(read) == want) {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF // Part shorter than its declared size
+			}
+			return n, err
+		}
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// File is [ReaderAt] of opened part files
+type File struct {
+	*ReaderAt
+	files []*os.File
+}
+
+// Open open files as parts in order
+func Open(names ...string) (*File, error) {
+	file := &File{}
+	parts := make([]Part, 0, len(names))
+	for _, name := range names {
+		osFile, err := os.Open(name)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		file.files = append(file.files, osFile)
+		stat, err := osFile.Stat()
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		parts = append(parts, Part{R: osFile, Size: stat.Size()})
+	}
+	file.ReaderAt = New(parts...)
+	return file, nil
+}
+
+// OpenSplit open parts name.001, name.002, ... until next part not exists
+func OpenSplit(name string) (*File, error) {
+	var names []string
+	for index := 1; ; index++ {
+		partName := fmt.Sprintf("%s.%03d", name, index)
+		if _, err := os.Stat(partName); errors.Is(err, fs.ErrNotExist) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		names = append(names, partName)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%w: %s.001", ErrNoParts, name)
+	}
+	return Open(names...)
+}
+
+// Close close all parts files
+func (file *File) Close() error {
+	var errs []error
+	for _, osFile := range file.files {
+		errs = append(errs, osFile.Close())
+	}
+	return errors.Join(errs...)
+}
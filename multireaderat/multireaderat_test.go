@@ -0,0 +1,91 @@
+package multireaderat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+// split data in parts at cuts, including empty parts to repeated cuts
+func split(data []byte, cuts ...int) []Part {
+	var parts []Part
+	start := 0
+	for _, cut := range append(cuts, len(data)) {
+		parts = append(parts, Part{R: bytes.NewReader(data[start:cut]), Size: int64(cut - start)})
+		start = cut
+	}
+	return parts
+}
+
+func TestReaderAt(t *testing.T) {
+	data := make([]byte, 10_000)
+	for index := range data {
+		data[index] = byte(rand.IntN(256))
+	}
+
+	r := New(split(data, 100, 100, 5000, 9999)...)
+	if r.Size() != int64(len(data)) {
+		t.Errorf("Wrong size %d", r.Size())
+		return
+	}
+	for range 500 {
+		off := rand.Int64N(int64(len(data)))
+		buff := make([]byte, rand.IntN(6000))
+		n, err := r.ReadAt(buff, off)
+		if err != nil && !(err == io.EOF && off+int64(len(buff)) > int64(len(data))) {
+			t.Errorf("Got error reading %d bytes at %d: %s", len(buff), off, err)
+			return
+		} else if !bytes.Equal(buff[:n], data[off:off+int64(n)]) {
+			t.Errorf("Wrong data reading %d bytes at %d", len(buff), off)
+			return
+		}
+	}
+
+	// Part smaller than declared size
+	short := New(Part{R: bytes.NewReader(data[:10]), Size: 20}, Part{R: bytes.NewReader(data[10:]), Size: 10})
+	if _, err := short.ReadAt(make([]byte, 30), 0); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Should get io.ErrUnexpectedEOF, got %v", err)
+		return
+	}
+}
+
+func TestOpenSplit(t *testing.T) {
+	data, err := os.ReadFile("../testdata/metadata_dir_sha256.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "app.phar")
+	for index, cut := range [][2]int{{0, 100}, {100, 200}, {200, len(data)}} {
+		if err := os.WriteFile(fmt.Sprintf("%s.%03d", base, index+1), data[cut[0]:cut[1]], 0o644); err != nil {
+			t.Error("Got error", err)
+			return
+		}
+	}
+
+	file, err := OpenSplit(base)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	defer file.Close()
+
+	// Signature and CRC checked across parts
+	if _, err := phargo.NewReader(file, file.Size()); err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	if _, err := OpenSplit(filepath.Join(dir, "missing.phar")); !errors.Is(err, ErrNoParts) {
+		t.Errorf("Should get ErrNoParts, got %v", err)
+		return
+	}
+}
@@ -0,0 +1,155 @@
+package phargo
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrWindowsName is returned when entry name can't be created on Windows
+var ErrWindowsName = errors.New("entry name not valid on Windows")
+
+// windowsReserved are device names Windows not allow as file name, with or without extension
+var windowsReserved = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// isWindowsIllegal report if char can't be used in Windows file names
+func isWindowsIllegal(char rune) bool {
+	return char < 32 || strings.ContainsRune(`<>:"\|?*`, char)
+}
+
+// checkWindowsName return [ErrWindowsName] if any slash separated component
+// of name has illegal characters, is a reserved device name or end with dot or space
+func checkWindowsName(name string) error {
+	for component := range strings.SplitSeq(name, "/") {
+		if index := strings.IndexFunc(component, isWindowsIllegal); index >= 0 {
+			return fmt.Errorf("%w: %q has illegal character %q", ErrWindowsName, name, component[index])
+		}
+		base, _, _ := strings.Cut(component, ".")
+		if windowsReserved[strings.ToUpper(strings.TrimRight(base, " "))] {
+			return fmt.Errorf("%w: %q use reserved name %q", ErrWindowsName, name, base)
+		}
+		if strings.HasSuffix(component, ".") || strings.HasSuffix(component, " ") {
+			return fmt.Errorf("%w: %q has component ending with dot or space", ErrWindowsName, name)
+		}
+	}
+	return nil
+}
+
+// mangleWindowsName replace illegal characters and trailing dots or spaces with "_",
+// and prefix reserved device names with "_", so name can be created on Windows
+func mangleWindowsName(name string) string {
+	components := strings.Split(name, "/")
+	for index, component := range components {
+		component = strings.Map(func(char rune) rune {
+			if isWindowsIllegal(char) {
+				return '_'
+			}
+			return char
+		}, component)
+
+		trimmed := strings.TrimRight(component, ". ")
+		component = trimmed + strings.Repeat("_", len(component)-len(trimmed))
+
+		base, _, _ := strings.Cut(component, ".")
+		if windowsReserved[strings.ToUpper(strings.TrimRight(base, " "))] {
+			component = "_" + component
+		}
+		components[index] = component
+	}
+	return strings.Join(components, "/")
+}
+
+// AbsolutePathPolicy control entries with absolute names, like "/etc/passwd" or `C:\Windows`
+type AbsolutePathPolicy int
+
+const (
+	AbsolutePathsDefault  AbsolutePathPolicy = iota // Reject on [Extract], preserve on [NewReaderWithOptions]
+	AbsolutePathsReject                             // Return [ErrUnsafePath]
+	AbsolutePathsStrip                              // Remove drive letter and leading slashes, making name relative
+	AbsolutePathsPreserve                           // Keep name, [Extract] write entry to absolute path
+)
+
+// isAbsoluteName report if name is absolute on unix or Windows, with leading slash,
+// backslash or drive letter
+func isAbsoluteName(name string) bool {
+	return strings.HasPrefix(name, "/") || strings.HasPrefix(name, `\`) || hasDriveLetter(name)
+}
+
+func hasDriveLetter(name string) bool {
+	return len(name) >= 2 && name[1] == ':' && ('a' <= name[0] && name[0] <= 'z' || 'A' <= name[0] && name[0] <= 'Z')
+}
+
+// stripAbsoluteName remove drive letter and leading separators from name, Windows names
+// have backslashes converted to slash
+func stripAbsoluteName(name string) string {
+	if hasDriveLetter(name) || strings.HasPrefix(name, `\`) {
+		name = strings.ReplaceAll(name, `\`, "/")
+		if hasDriveLetter(name) {
+			name = name[2:]
+		}
+	}
+	return path.Clean(strings.TrimLeft(name, "/"))
+}
+
+// applyAbsolutePolicy return name after policy, or [ErrUnsafePath] if absolute names are rejected
+func applyAbsolutePolicy(name string, policy AbsolutePathPolicy) (string, error) {
+	if !isAbsoluteName(name) {
+		return name, nil
+	}
+
+	switch policy {
+	case AbsolutePathsStrip:
+		if stripped := stripAbsoluteName(name); stripped != "." {
+			return stripped, nil
+		}
+		return "", fmt.Errorf("%w: %q is empty without root", ErrUnsafePath, name)
+	case AbsolutePathsPreserve:
+		return name, nil
+	default:
+		return "", fmt.Errorf("%w: absolute path %q", ErrUnsafePath, name)
+	}
+}
+
+// ErrDuplicateEntry is returned when archive has more than one entry with same name
+// and [DuplicatesReject] is set
+var ErrDuplicateEntry = errors.New("duplicate entry")
+
+// DuplicatePolicy control entries with same name in manifest, all duplicates are reported in [Phar.Issues]
+type DuplicatePolicy int
+
+const (
+	DuplicatesKeepAll  DuplicatePolicy = iota // Keep all entries in [Phar.Files], lookup by name find first
+	DuplicatesReject                          // Return [ErrDuplicateEntry]
+	DuplicatesKeepLast                        // Keep only last entry with name, like PHP
+)
+
+// applyDuplicatePolicy report entries with same name and remove them with policy
+func applyDuplicatePolicy(files []*File, policy DuplicatePolicy) ([]*File, []Issue, error) {
+	last := make(map[string]int, len(files))
+	var issues []Issue
+	for index, file := range files {
+		if previous, ok := last[file.Filename]; ok {
+			if policy == DuplicatesReject {
+				return nil, nil, fmt.Errorf("%w: %q at %d and %d", ErrDuplicateEntry, file.Filename, previous, index)
+			}
+			issues = append(issues, Issue{Kind: IssueDuplicate, Entry: file.Filename, Detail: fmt.Sprintf("is duplicated at %d and %d", previous, index)})
+		}
+		last[file.Filename] = index
+	}
+
+	if len(issues) == 0 || policy != DuplicatesKeepLast {
+		return files, issues, nil
+	}
+	kept := make([]*File, 0, len(last))
+	for index, file := range files {
+		if last[file.Filename] == index {
+			kept = append(kept, file)
+		}
+	}
+	return kept, issues, nil
+}
@@ -1,34 +1,151 @@
 package phargo
 
-import "io"
+import (
+	"bufio"
+	"compress/flate"
+	"io"
+	"io/fs"
+	"slices"
+	"sync"
+)
+
+// ReadBufferSize is buffer size to read entries content and hash archive,
+// larger buffers reduce ReadAt calls on slow readers
+var ReadBufferSize = 64 * 1024
 
 // Parsed PHAR-file
+//
+// [File.Open] and [fs.FS] methods are safe for concurrent use by multiple goroutines,
+// each open entry reads with its own offset from the underlying [io.ReaderAt],
+// which must support parallel ReadAt calls as required by its contract.
+// Files returned by Open are not safe for concurrent use, and [Phar.EnableCache]
+// must be called before sharing the archive between goroutines.
 type Phar struct {
-	Menifest  *Manifest
+	Manifest  *Manifest
 	Signature *Signature
-	Files     []*File
+	Files     []*File  // Entries in manifest order
+	Issues    []Issue  // Non fatal problems found while parsing
+	Stub      StubInfo // Stub kind, alias and PHP version
+
+	// Deprecated: use Manifest, same value kept to old code until next release.
+	Menifest *Manifest `json:"-"`
+
+	verified        bool // Signature hash checked with archive content
+	caseInsensitive bool // Open match names ignoring case
+
+	r    io.ReaderAt // Archive reader, to check signature after parse
+	size int64
+
+	indexMutex sync.Mutex
+	index      *fsIndex // Names index to fs.FS methods, built on first use
 }
 
-// readerAtAdapter wraps an io.ReaderAt to implement io.Reader.
-type readerAtAdapter struct {
-	reader io.ReaderAt
-	offset int64 // Current read position
+// copyBufferSize is size of pooled buffers, large entries copy with fewer calls
+const copyBufferSize = 1 << 20
+
+// copyBufferPool reuse buffers to copy entries content
+var copyBufferPool = sync.Pool{
+	New: func() any {
+		buff := make([]byte, copyBufferSize)
+		return &buff
+	},
+}
+
+// CopyBuffer copy src to dst like [io.Copy] with pooled buffer, same used to copy entries content,
+// so callers copying big files don't allocate own buffers
+func CopyBuffer(dst io.Writer, src io.Reader) (int64, error) {
+	return copyBuffer(dst, src)
+}
+
+// copyBuffer copy src to dst with buffer from [copyBufferPool]
+func copyBuffer(dst io.Writer, src io.Reader) (int64, error) {
+	buff := copyBufferPool.Get().(*[]byte)
+	defer copyBufferPool.Put(buff)
+	return io.CopyBuffer(dst, src, *buff)
+}
+
+// flateReaderPool reuse deflate decompressors, readers are Reset to each entry
+var flateReaderPool sync.Pool
+
+// pooledFlateReader return deflate reader to [flateReaderPool] on Close
+type pooledFlateReader struct {
+	reader io.ReadCloser
 }
 
-// Read implements the io.Reader interface.
-func (r *readerAtAdapter) Read(p []byte) (n int, err error) {
-	// Use ReadAt with the current offset.
-	n, err = r.reader.ReadAt(p, r.offset)
-	// Advance the offset for the next read.
-	r.offset += int64(n)
-	// Return bytes read and any error (including io.EOF).
-	return n, err
+func newFlateReader(r io.Reader) io.ReadCloser {
+	if reader, ok := flateReaderPool.Get().(io.ReadCloser); ok {
+		reader.(flate.Resetter).Reset(r, nil)
+		return &pooledFlateReader{reader}
+	}
+	return &pooledFlateReader{flate.NewReader(r)}
 }
 
-// NewReaderFromReaderAt creates an io.Reader from an io.ReaderAt, starting at offset 0.
-func newReaderFromReaderAt(r io.ReaderAt) io.Reader {
-	return &readerAtAdapter{reader: r, offset: 0}
+func (r *pooledFlateReader) Read(p []byte) (int, error) {
+	if r.reader == nil {
+		return 0, fs.ErrClosed
+	}
+	return r.reader.Read(p)
 }
-func newReaderFromReaderAtOffset(r io.ReaderAt, offset int64) io.Reader {
-	return &readerAtAdapter{reader: r, offset: offset}
+
+func (r *pooledFlateReader) Close() error {
+	if r.reader == nil {
+		return nil
+	}
+	err := r.reader.Close()
+	flateReaderPool.Put(r.reader)
+	r.reader = nil
+	return err
+}
+
+// newSectionReader return buffered reader of r from offset with n bytes
+func newSectionReader(r io.ReaderAt, offset, n int64) io.Reader {
+	return bufio.NewReaderSize(io.NewSectionReader(r, offset, n), int(min(int64(ReadBufferSize), n)))
+}
+
+// readAtChunk is max buffer allocated to lengths from archive before data is read
+const readAtChunk = 1 << 20
+
+// readAt read n bytes at offset, growing buffer while data is read, so lengths
+// declared in archive can't allocate more than archive real size
+func readAt(r io.ReaderAt, offset, n int64) ([]byte, error) {
+	if n <= readAtChunk {
+		buff := make([]byte, n)
+		read, err := r.ReadAt(buff, offset)
+		if err == io.EOF && int64(read) == n {
+			err = nil
+		}
+		return buff[:read], err
+	}
+
+	var buff []byte
+	for int64(len(buff)) < n {
+		chunk := int(min(n-int64(len(buff)), readAtChunk))
+		buff = slices.Grow(buff, chunk)
+		read, err := r.ReadAt(buff[len(buff):len(buff)+chunk], offset+int64(len(buff)))
+		buff = buff[:len(buff)+read]
+		if err != nil && (err != io.EOF || read < chunk) {
+			return buff, err
+		}
+	}
+	return buff, nil
+}
+
+// memReaderAt is [io.ReaderAt] of data placed at base offset
+type memReaderAt struct {
+	data []byte
+	base int64
+}
+
+// Size return end offset of data
+func (mem *memReaderAt) Size() int64 { return mem.base + int64(len(mem.data)) }
+
+func (mem *memReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < mem.base || off-mem.base > int64(len(mem.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, mem.data[off-mem.base:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
 }
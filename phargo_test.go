@@ -0,0 +1,65 @@
+package phargo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/crc32"
+)
+
+// testEntry is file entry to [buildPhar]
+type testEntry struct {
+	name  string
+	data  []byte
+	crc   uint32 // Zero to compute from data
+	flags uint32 // Zero to EntryPermDefFile
+}
+
+// buildPhar make unsigned phar in memory with uncompressed entries
+func buildPhar(entries ...testEntry) []byte {
+	le := binary.LittleEndian
+	manifest := le.AppendUint32(nil, uint32(len(entries)))
+	manifest = append(manifest, 0x11, 0x00) // API version 1.1.0
+	manifest = le.AppendUint32(manifest, 0) // Global flags
+	manifest = le.AppendUint32(manifest, 0) // Alias length
+	manifest = le.AppendUint32(manifest, 0) // Metadata length
+
+	var content []byte
+	for _, entry := range entries {
+		if entry.crc == 0 {
+			entry.crc = crc32.ChecksumIEEE(entry.data)
+		}
+		if entry.flags == 0 {
+			entry.flags = EntryPermDefFile
+		}
+		manifest = le.AppendUint32(manifest, uint32(len(entry.name)))
+		manifest = append(manifest, entry.name...)
+		manifest = le.AppendUint32(manifest, uint32(len(entry.data)))
+		manifest = le.AppendUint32(manifest, 1516786273)
+		manifest = le.AppendUint32(manifest, uint32(len(entry.data)))
+		manifest = le.AppendUint32(manifest, entry.crc)
+		manifest = le.AppendUint32(manifest, entry.flags)
+		manifest = le.AppendUint32(manifest, 0)
+		content = append(content, entry.data...)
+	}
+
+	var phar bytes.Buffer
+	phar.WriteString("<?php __HALT_COMPILER(); ?>\r\n")
+	binary.Write(&phar, le, uint32(len(manifest)))
+	phar.Write(manifest)
+	phar.Write(content)
+	return phar.Bytes()
+}
+
+// signPhar set signed flag in phar from [buildPhar] and append SHA256 signature
+func signPhar(data []byte) []byte {
+	offset, _ := getOffset(bytes.NewReader(data), stubScanChunkSize, haltCompiler)
+	offset += 4 + 4 + 2 // Manifest length, entries count and API version
+	le := binary.LittleEndian
+	le.PutUint32(data[offset:], le.Uint32(data[offset:])|uint32(GlobalSigned))
+
+	hash := sha256.Sum256(data)
+	data = append(data, hash[:]...)
+	data = le.AppendUint32(data, uint32(SignatureSHA256))
+	return append(data, "GBMB"...)
+}
@@ -0,0 +1,276 @@
+// Package phpserialize decode values in PHP serialize() format, like phar archive and entries metadata,
+// to Go values:
+//
+//	N;          nil
+//	b:1;        bool
+//	i:123;      int64
+//	d:0.5;      float64
+//	s:1:"a";    string
+//	a:1:{...}   []any if keys are 0, 1, 2..., else map[string]any
+//	O:3:"Foo":1:{...} *Object
+//
+// References "r:n;" and "R:n;" are decoded to copy of referenced value.
+package phpserialize
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrSyntax is returned when data is not valid serialized value
+var ErrSyntax = errors.New("invalid serialized data")
+
+// ErrUnsupported is returned to values without Go representation, like "C:" custom serialized objects
+var ErrUnsupported = errors.New("unsupported serialized value")
+
+// maxDepth limit nested arrays and objects
+const maxDepth = 512
+
+// Object is PHP object, Fields have visibility prefix removed from private and protected properties names
+type Object struct {
+	Class  string
+	Fields map[string]any
+}
+
+// Unmarshal decode one serialized value, data after value is an error
+func Unmarshal(data []byte) (any, error) {
+	d := &decoder{data: data}
+	value, err := d.value(0)
+	if err != nil {
+		return nil, err
+	} else if d.off != len(d.data) {
+		return nil, d.errorf("unexpected data after value")
+	}
+	return value, nil
+}
+
+type decoder struct {
+	data   []byte
+	off    int
+	values []any // Decoded values to references, 1-indexed in PHP
+}
+
+func (d *decoder) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at offset %d: %s", ErrSyntax, d.off, fmt.Sprintf(format, args...))
+}
+
+// expect consume byte c
+func (d *decoder) expect(c byte) error {
+	if d.off >= len(d.data) || d.data[d.off] != c {
+		return d.errorf("expected %q", c)
+	}
+	d.off++
+	return nil
+}
+
+// until return bytes before c and consume c
+func (d *decoder) until(c byte) (string, error) {
+	for index := d.off; index < len(d.data); index++ {
+		if d.data[index] == c {
+			token := string(d.data[d.off:index])
+			d.off = index + 1
+			return token, nil
+		}
+	}
+	return "", d.errorf("expected %q", c)
+}
+
+// length read unsigned length ended by ":"
+func (d *decoder) length() (int, error) {
+	token, err := d.until(':')
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(token)
+	if err != nil || n < 0 || n > len(d.data) {
+		return 0, d.errorf("invalid length %q", token)
+	}
+	return n, nil
+}
+
+// quoted read "len:"string"" body
+func (d *decoder) quoted() (string, error) {
+	n, err := d.length()
+	if err != nil {
+		return "", err
+	} else if err = d.expect('"'); err != nil {
+		return "", err
+	} else if len(d.data)-d.off < n {
+		return "", d.errorf("string length %d out of data", n)
+	}
+	s := string(d.data[d.off : d.off+n])
+	d.off += n
+	return s, d.expect('"')
+}
+
+func (d *decoder) value(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, d.errorf("nested more than %d levels", maxDepth)
+	} else if len(d.data)-d.off < 2 {
+		return nil, d.errorf("unexpected end of data")
+	}
+
+	kind := d.data[d.off]
+	d.off++
+	if kind == 'N' {
+		d.values = append(d.values, nil)
+		return nil, d.expect(';')
+	} else if err := d.expect(':'); err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case 'b':
+		token, err := d.until(';')
+		if err != nil {
+			return nil, err
+		} else if token != "0" && token != "1" {
+			return nil, d.errorf("invalid bool %q", token)
+		}
+		d.values = append(d.values, token == "1")
+		return token == "1", nil
+	case 'i':
+		token, err := d.until(';')
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.ParseInt(token, 10, 64)
+		if err != nil {
+			return nil, d.errorf("invalid int %q", token)
+		}
+		d.values = append(d.values, n)
+		return n, nil
+	case 'd':
+		token, err := d.until(';')
+		if err != nil {
+			return nil, err
+		}
+		f, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, d.errorf("invalid float %q", token)
+		}
+		d.values = append(d.values, f)
+		return f, nil
+	case 's':
+		s, err := d.quoted()
+		if err != nil {
+			return nil, err
+		}
+		d.values = append(d.values, s)
+		return s, d.expect(';')
+	case 'r', 'R':
+		token, err := d.until(';')
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(token)
+		if err != nil || n < 1 || n > len(d.values) {
+			return nil, d.errorf("invalid reference %q", token)
+		}
+		value := d.values[n-1]
+		if kind == 'r' {
+			d.values = append(d.values, value)
+		}
+		return value, nil
+	case 'a':
+		index := len(d.values)
+		d.values = append(d.values, nil)
+		fields, keys, err := d.fields(depth)
+		if err != nil {
+			return nil, err
+		}
+		var value any = fields
+		if list, ok := asList(fields, keys); ok {
+			value = list
+		}
+		d.values[index] = value
+		return value, nil
+	case 'O':
+		class, err := d.quoted()
+		if err != nil {
+			return nil, err
+		} else if err = d.expect(':'); err != nil {
+			return nil, err
+		}
+		obj := &Object{Class: class}
+		d.values = append(d.values, obj)
+		fields, _, err := d.fields(depth)
+		if err != nil {
+			return nil, err
+		}
+		obj.Fields = make(map[string]any, len(fields))
+		for name, value := range fields {
+			// Private "\0Class\0name" and protected "\0*\0name" properties
+			if strings.HasPrefix(name, "\x00") {
+				if _, after, ok := strings.Cut(name[1:], "\x00"); ok {
+					name = after
+				}
+			}
+			obj.Fields[name] = value
+		}
+		return obj, nil
+	case 'C', 'E':
+		return nil, fmt.Errorf("%w: %q at offset %d", ErrUnsupported, kind, d.off-2)
+	}
+	return nil, fmt.Errorf("%w at offset %d: unknown type %q", ErrSyntax, d.off-2, kind)
+}
+
+// fields read "n:{key;value...}" of array or object, returning keys in order
+func (d *decoder) fields(depth int) (map[string]any, []string, error) {
+	n, err := d.length()
+	if err != nil {
+		return nil, nil, err
+	} else if err = d.expect('{'); err != nil {
+		return nil, nil, err
+	}
+
+	fields, keys := make(map[string]any, min(n, 64)), make([]string, 0, min(n, 64))
+	for range n {
+		if d.off+1 >= len(d.data) || d.data[d.off+1] != ':' {
+			return nil, nil, d.errorf("expected key")
+		}
+		var key string
+		switch d.data[d.off] {
+		case 'i':
+			d.off += 2
+			if key, err = d.until(';'); err != nil {
+				return nil, nil, err
+			} else if _, err := strconv.ParseInt(key, 10, 64); err != nil {
+				return nil, nil, d.errorf("invalid int key %q", key)
+			}
+		case 's':
+			d.off += 2
+			if key, err = d.quoted(); err != nil {
+				return nil, nil, err
+			} else if err = d.expect(';'); err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, d.errorf("invalid key type %q", d.data[d.off])
+		}
+
+		value, err := d.value(depth + 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, ok := fields[key]; !ok {
+			keys = append(keys, key)
+		}
+		fields[key] = value
+	}
+	return fields, keys, d.expect('}')
+}
+
+// asList return array values if keys are 0, 1, 2... in order
+func asList(fields map[string]any, keys []string) ([]any, bool) {
+	list := make([]any, len(keys))
+	for index, key := range keys {
+		if key != strconv.Itoa(index) {
+			return nil, false
+		}
+		list[index] = fields[key]
+	}
+	return list, true
+}
@@ -0,0 +1,74 @@
+package phpserialize
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	for _, test := range []struct {
+		data     string
+		expected any
+	}{
+		{`N;`, nil},
+		{`b:1;`, true},
+		{`b:0;`, false},
+		{`i:-42;`, int64(-42)},
+		{`d:0.5;`, 0.5},
+		{`d:-INF;`, math.Inf(-1)},
+		{`s:0:"";`, ""},
+		{`s:5:"a"b;c";`, `a"b;c`},
+		{`s:2:"é";`, "é"},
+		{`a:0:{}`, []any{}},
+		{`a:2:{i:0;s:1:"x";i:1;i:2;}`, []any{"x", int64(2)}},
+		{`a:2:{i:1;s:1:"x";i:0;i:2;}`, map[string]any{"1": "x", "0": int64(2)}},
+		{`a:2:{s:1:"a";i:123;s:1:"b";a:1:{i:0;N;}}`, map[string]any{"a": int64(123), "b": []any{nil}}},
+		{`a:2:{i:0;s:1:"x";i:1;r:2;}`, []any{"x", "x"}},
+		{
+			`O:3:"Foo":3:{s:1:"a";i:1;s:4:"` + "\x00*\x00b" + `";b:1;s:6:"` + "\x00Foo\x00c" + `";N;}`,
+			&Object{Class: "Foo", Fields: map[string]any{"a": int64(1), "b": true, "c": nil}},
+		},
+	} {
+		value, err := Unmarshal([]byte(test.data))
+		if err != nil {
+			t.Errorf("%q: %s", test.data, err)
+			return
+		} else if !reflect.DeepEqual(value, test.expected) {
+			t.Errorf("%q: got %#v, expected %#v", test.data, value, test.expected)
+			return
+		}
+	}
+
+	if value, err := Unmarshal([]byte(`d:NAN;`)); err != nil || !math.IsNaN(value.(float64)) {
+		t.Errorf("Wrong NaN: %v, %v", value, err)
+		return
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, data := range []string{
+		``, `N`, `b:2;`, `i:x;`, `i:1`, `s:5:"abc";`, `s:-1:"";`, `s:1:"ab";`,
+		`a:1:{}`, `a:1:{i:0;i:1;`, `a:1:{d:0.5;i:1;}`, `a:1:{i:0;r:9;}`, `x:1;`, `i:1;i:2;`,
+	} {
+		if _, err := Unmarshal([]byte(data)); !errors.Is(err, ErrSyntax) {
+			t.Errorf("%q: expected syntax error, got %v", data, err)
+			return
+		}
+	}
+
+	if _, err := Unmarshal([]byte(`C:3:"Foo":0:{}`)); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Expected unsupported error, got %v", err)
+		return
+	}
+
+	deep := make([]byte, 0, (maxDepth+2)*9)
+	for range maxDepth + 2 {
+		deep = append(deep, "a:1:{i:0;"...)
+	}
+	if _, err := Unmarshal(deep); !errors.Is(err, ErrSyntax) {
+		t.Errorf("Expected depth error, got %v", err)
+		return
+	}
+}
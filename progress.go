@@ -0,0 +1,48 @@
+package phargo
+
+import "sync"
+
+// Progress phases reported to [ProgressFunc]
+const (
+	ProgressManifest  = "manifest"  // Entries parsed, by entries count
+	ProgressSignature = "signature" // Archive bytes hashed to check signature
+	ProgressCRC       = "crc"       // Entries content checked with CRC, by uncompressed bytes
+	ProgressExtract   = "extract"   // Entries content written to disk, by uncompressed bytes
+)
+
+// ProgressFunc is called while parsing, verifying or extracting archive with
+// done and total units of phase, like to show progress bars. It may be called
+// from many goroutines, but never concurrently.
+type ProgressFunc func(phase string, done, total int64)
+
+// progressCounter accumulate done units of phase and report them to fn
+type progressCounter struct {
+	fn          ProgressFunc
+	phase       string
+	done, total int64
+	sync.Mutex
+}
+
+func newProgressCounter(fn ProgressFunc, phase string, total int64) *progressCounter {
+	if fn == nil {
+		return nil
+	}
+	return &progressCounter{fn: fn, phase: phase, total: total}
+}
+
+// add n units to done, nil counter ignore calls
+func (counter *progressCounter) add(n int64) {
+	if counter == nil {
+		return
+	}
+	counter.Lock()
+	defer counter.Unlock()
+	counter.done += n
+	counter.fn(counter.phase, counter.done, counter.total)
+}
+
+// Write count written bytes, to be used with [io.MultiWriter]
+func (counter *progressCounter) Write(p []byte) (int, error) {
+	counter.add(int64(len(p)))
+	return len(p), nil
+}
@@ -0,0 +1,37 @@
+package phargo
+
+import (
+	"os"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	data, err := os.ReadFile("./testdata/metadata_dir_sha256.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+
+	last := map[string][2]int64{}
+	progress := func(phase string, done, total int64) {
+		if previous := last[phase]; done < previous[0] || done > total {
+			t.Errorf("Wrong %s progress: %d of %d after %d", phase, done, total, previous[0])
+		}
+		last[phase] = [2]int64{done, total}
+	}
+
+	file, err := NewReaderWithOptions(&memReaderAt{data: data}, int64(len(data)), ReaderOptions{Progress: progress})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if err := Extract(file, t.TempDir(), WithProgress(progress)); err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	for _, phase := range []string{ProgressManifest, ProgressSignature, ProgressCRC, ProgressExtract} {
+		if got, ok := last[phase]; !ok || got[0] != got[1] || got[1] == 0 {
+			t.Errorf("%s progress should end at total, got %v", phase, got)
+		}
+	}
+}
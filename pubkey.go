@@ -0,0 +1,110 @@
+package phargo
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrPublicKey is returned when public key of OpenSSL signed archive is invalid
+var ErrPublicKey = errors.New("invalid public key")
+
+// VerifyOpenSSLSignature check archive OpenSSL signature, RSA PKCS #1 v1.5 over SHA-1,
+// SHA-256 or SHA-512 of archive until signature, with RSA public key from [ParsePublicKey].
+//
+// Archives not signed with OpenSSL return [ErrInvalidSignature].
+func VerifyOpenSSLSignature(r io.ReaderAt, size int64, pub crypto.PublicKey) error {
+	rsaKey, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: %T is not RSA key", ErrPublicKey, pub)
+	}
+
+	signature, err := getSignature(r, size, false, nil)
+	if err != nil && err != ErrOpenssl {
+		return err
+	}
+
+	var hashType crypto.Hash
+	switch signature.Signature {
+	case SignatureOpenSSL:
+		hashType = crypto.SHA1
+	case SignatureOpenSSLSha256:
+		hashType = crypto.SHA256
+	case SignatureOpenSSLSha512:
+		hashType = crypto.SHA512
+	default:
+		return fmt.Errorf("%w: %s is not OpenSSL signature", ErrInvalidSignature, signature.Signature)
+	}
+
+	// Signature is followed by its length, flags and GBMB
+	hashEnd := size - int64(len(signature.Hash)) - int64(pharSignatureLenLen) - int64(pharSignatureStubLen)
+	digest := hashType.New()
+	if _, err := io.CopyN(digest, newSectionReader(r, 0, hashEnd), hashEnd); err != nil {
+		return err
+	}
+	if err := rsa.VerifyPKCS1v15(rsaKey, hashType, digest.Sum(nil), signature.Hash); err != nil {
+		return fmt.Errorf("%w: %s signature not match public key", ErrInvalidSignature, signature.Signature)
+	}
+	return nil
+}
+
+// PublicKeyPath return path of public key PHP load to verify OpenSSL signed archive,
+// archive path with ".pubkey" suffix
+func PublicKeyPath(pharPath string) string {
+	return pharPath + ".pubkey"
+}
+
+// ParsePublicKey parse first PEM block of ".pubkey" file, "PUBLIC KEY" or "RSA PUBLIC KEY".
+// PHP sign archives only with RSA keys, so other key types return [ErrPublicKey].
+func ParsePublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%w: no PEM block", ErrPublicKey)
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrPublicKey, err)
+		}
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T is not RSA key", ErrPublicKey, key)
+		}
+		return rsaKey, nil
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrPublicKey, err)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected PEM block %q", ErrPublicKey, block.Type)
+	}
+}
+
+// MarshalPublicKey encode key as "PUBLIC KEY" PEM block, accepted by PHP
+func MarshalPublicKey(key *rsa.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPublicKey, err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// PublicKeyFingerprint return SHA-256 of key PKIX DER encoding, same of
+// `openssl pkey -pubin -outform DER | sha256sum`
+func PublicKeyFingerprint(key *rsa.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPublicKey, err)
+	}
+	sum := sha256.Sum256(der)
+	return sum[:], nil
+}
@@ -0,0 +1,109 @@
+package phargo
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+func TestParsePublicKey(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	pkix, err := MarshalPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&private.PublicKey)})
+
+	var fingerprints [][]byte
+	for _, data := range [][]byte{pkix, pkcs1} {
+		key, err := ParsePublicKey(data)
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		} else if !key.Equal(&private.PublicKey) {
+			t.Error("Parsed key differ")
+			return
+		}
+		fingerprint, _ := PublicKeyFingerprint(key)
+		fingerprints = append(fingerprints, fingerprint)
+	}
+	if !bytes.Equal(fingerprints[0], fingerprints[1]) {
+		t.Error("Fingerprint depend on PEM encoding")
+		return
+	}
+
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	for _, data := range [][]byte{[]byte("not pem"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})} {
+		if _, err := ParsePublicKey(data); !errors.Is(err, ErrPublicKey) {
+			t.Errorf("Should get ErrPublicKey, got %v", err)
+			return
+		}
+	}
+}
+
+// opensslSignPhar set signed flag in phar from [buildPhar] and append OpenSSL signature made with key
+func opensslSignPhar(data []byte, key *rsa.PrivateKey, flag SignatureFlag, hashType crypto.Hash) []byte {
+	offset, _ := getOffset(bytes.NewReader(data), stubScanChunkSize, haltCompiler)
+	offset += 4 + 4 + 2 // Manifest length, entries count and API version
+	le := binary.LittleEndian
+	le.PutUint32(data[offset:], le.Uint32(data[offset:])|uint32(GlobalSigned))
+
+	digest := hashType.New()
+	digest.Write(data)
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, key, hashType, digest.Sum(nil))
+	data = append(data, signature...)
+	data = le.AppendUint32(data, uint32(len(signature)))
+	data = le.AppendUint32(data, uint32(flag))
+	return append(data, "GBMB"...)
+}
+
+func TestVerifyOpenSSLSignature(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	for flag, hashType := range map[SignatureFlag]crypto.Hash{
+		SignatureOpenSSL:       crypto.SHA1,
+		SignatureOpenSSLSha256: crypto.SHA256,
+		SignatureOpenSSLSha512: crypto.SHA512,
+	} {
+		data := opensslSignPhar(buildPhar(testEntry{name: "a.txt", data: []byte("AAAA")}), key, flag, hashType)
+		if err := VerifyOpenSSLSignature(bytes.NewReader(data), int64(len(data)), &key.PublicKey); err != nil {
+			t.Errorf("%s: Got error %s", flag, err)
+			return
+		} else if err := VerifyOpenSSLSignature(bytes.NewReader(data), int64(len(data)), &other.PublicKey); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: Should get ErrInvalidSignature with other key, got %v", flag, err)
+			return
+		}
+
+		data[bytes.Index(data, []byte("AAAA"))] = 'X'
+		if err := VerifyOpenSSLSignature(bytes.NewReader(data), int64(len(data)), &key.PublicKey); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: Should get ErrInvalidSignature to changed content, got %v", flag, err)
+			return
+		}
+	}
+
+	data := signPhar(buildPhar(testEntry{name: "a.txt", data: []byte("AAAA")}))
+	if err := VerifyOpenSSLSignature(bytes.NewReader(data), int64(len(data)), &key.PublicKey); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Should get ErrInvalidSignature to sha256 signature, got %v", err)
+		return
+	}
+}
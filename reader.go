@@ -1,66 +1,349 @@
 package phargo
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"io"
+	"io/fs"
 	"os"
+	"runtime"
+	"sync"
+	"time"
 )
 
+// ErrBadCRC is returned when entry content not match CRC from manifest
+var ErrBadCRC = errors.New("bad CRC")
+
+// CRCError is entry with content not matching manifest CRC, and unwrap to [ErrBadCRC]
+type CRCError struct {
+	Filename string
+	Expected uint32
+	Got      uint32
+}
+
+func (err *CRCError) Error() string {
+	return fmt.Sprintf("%s has %s, expect: %d, received: %d", err.Filename, ErrBadCRC, err.Expected, err.Got)
+}
+
+func (err *CRCError) Unwrap() error { return ErrBadCRC }
+
 // Parse phar file from [*os.File]
 func NewReaderFromFile(file *os.File) (*Phar, error) {
 	stat, err := file.Stat()
 	if err != nil {
-		return nil, fmt.Errorf("cannot get file stats: %s", err)
+		return nil, fmt.Errorf("cannot get file stats: %w", err)
 	}
 	return NewReader(file, stat.Size())
 }
 
+// Parse phar file name from fsys, like [embed.FS].
+//
+// Files implementing [io.ReaderAt] are read in place and kept open while archive
+// is used, other files are read to memory.
+func OpenFS(fsys fs.FS, name string) (*Phar, error) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot get file stats: %w", err)
+	}
+
+	if r, ok := file.(io.ReaderAt); ok {
+		pharInfo, err := NewReader(r, stat.Size())
+		if err != nil {
+			file.Close()
+		}
+		return pharInfo, err
+	}
+
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read file: %w", err)
+	}
+	return NewReader(bytes.NewReader(data), int64(len(data)))
+}
+
+// ReaderOptions configure archive parsing
+type ReaderOptions struct {
+	// SkipCRC not check entries content with manifest CRC
+	SkipCRC bool
+
+	// Trusted skip signature and CRC checks, archive content is never read
+	// while parsing, to list archives from slow or remote readers.
+	// Signature algorithm and hash are still reported.
+	Trusted bool
+
+	// AbsolutePaths set policy to entries with absolute names,
+	// default preserve names as stored in archive.
+	AbsolutePaths AbsolutePathPolicy
+
+	// RejectInvalidNames return [ErrInvalidName] to entries names with invalid UTF-8,
+	// NUL or control characters, instead of only report them in [Phar.Issues]
+	RejectInvalidNames bool
+
+	// ReportCRC report all entries with bad CRC in [Phar.Issues],
+	// instead of return [CRCError] of first one
+	ReportCRC bool
+
+	// CheckTimestamps report entries with negative timestamps, before 1980 or
+	// more than a day in future in [Phar.Issues]
+	CheckTimestamps bool
+
+	// Duplicates set policy to entries with same name, default keep all
+	Duplicates DuplicatePolicy
+
+	// CaseInsensitive make [Phar.Open] match names ignoring case, exact name is
+	// preferred, then first matching name in byte order
+	CaseInsensitive bool
+
+	// Lenient report entries count inconsistent with manifest length and truncated
+	// content or signature in [Phar.Issues], instead of return [ErrBadManifest] or [ErrTruncated]
+	Lenient bool
+
+	// Progress is called while entries are parsed, signature is hashed and CRC checked,
+	// with [ProgressManifest], [ProgressSignature] and [ProgressCRC] phases
+	Progress ProgressFunc
+
+	// Parser limits, zero is unlimited. Archives exceeding any limit
+	// return [ErrLimitExceeded] before buffers are allocated.
+	MaxEntries     int64 // Entries count in manifest
+	MaxFilenameLen int64 // Entry name length
+	MaxMetadataLen int64 // Archive or entry serialized metadata length
+	MaxAliasLen    int64 // Archive alias length
+	MaxManifestLen int64 // Manifest length, with all entries
+}
+
+// ErrBadManifest is returned when manifest fields are inconsistent with archive
+var ErrBadManifest = errors.New("bad manifest")
+
+// ErrTruncated is returned when archive ends before manifest, content or signature
+var ErrTruncated = errors.New("archive truncated")
+
+// TruncatedError report where truncated archive ends, and unwrap to [ErrTruncated]
+type TruncatedError struct {
+	Section   string // "manifest", "content" or "signature"
+	Expected  int64  // Bytes needed to end of section
+	Available int64  // Bytes in archive available to section
+	LastEntry *File  // Last entry with whole content in archive, nil if none
+}
+
+func (err *TruncatedError) Error() string {
+	if err.LastEntry == nil {
+		return fmt.Sprintf("%s: %s need %d bytes, archive has %d", ErrTruncated, err.Section, err.Expected, err.Available)
+	}
+	return fmt.Sprintf("%s: %s need %d bytes, archive has %d, last complete entry is %s", ErrTruncated, err.Section, err.Expected, err.Available, err.LastEntry.Filename)
+}
+
+func (err *TruncatedError) Unwrap() error { return ErrTruncated }
+
+// newTruncatedError make [TruncatedError] finding last entry with content ending before available
+func newTruncatedError(section string, expected, available int64, files []*File, contentOffset int64) *TruncatedError {
+	err := &TruncatedError{Section: section, Expected: expected, Available: available}
+	for _, file := range files {
+		if contentOffset += file.dataLen; contentOffset > available {
+			break
+		}
+		err.LastEntry = file
+	}
+	return err
+}
+
+// ErrLimitExceeded is returned when archive exceed [ReaderOptions] limits
+var ErrLimitExceeded = errors.New("parser limit exceeded")
+
+// checkLimit return [ErrLimitExceeded] if value is bigger than non zero limit
+func checkLimit(name string, value, limit int64) error {
+	if limit > 0 && value > limit {
+		return fmt.Errorf("%w: %s %d bigger than %d", ErrLimitExceeded, name, value, limit)
+	}
+	return nil
+}
+
+func (options ReaderOptions) absolutePaths() AbsolutePathPolicy {
+	if options.AbsolutePaths == AbsolutePathsDefault {
+		return AbsolutePathsPreserve
+	}
+	return options.AbsolutePaths
+}
+
 // Parse phar file
 func NewReader(r io.ReaderAt, size int64) (*Phar, error) {
-	manifest, offset, err := ParseManifest(r)
+	return NewReaderWithOptions(r, size, ReaderOptions{})
+}
+
+// Parse phar file with options
+func NewReaderWithOptions(r io.ReaderAt, size int64, options ReaderOptions) (*Phar, error) {
+	manifest, offset, err := parseManifest(r, size, options)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse manifest: %s", err)
+		return nil, fmt.Errorf("cannot parse manifest: %w", err)
 	}
 
 	// Start struct
-	filePhar := &Phar{Menifest: manifest, Files: []*File{}}
-	if manifest.IsSigned {
-		if filePhar.Signature, err = GetSignature(r, size); err != nil {
-			if err != ErrOpenssl {
-				return nil, err
-			}
+	filePhar := &Phar{Manifest: manifest, Menifest: manifest, Files: []*File{}, caseInsensitive: options.CaseInsensitive, r: r, size: size}
+	stub, err := readStub(r, manifest.offset)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read stub: %w", err)
+	}
+	filePhar.Stub = ParseStub(stub)
+	// Each entry has at least 28 bytes in manifest, after 14 bytes of archive fields
+	if minLength := int64(manifest.EntitiesCount)*28 + 14; minLength > int64(manifest.Length) {
+		err := fmt.Errorf("%w: %d entries need at least %d bytes, manifest length is %d", ErrBadManifest, manifest.EntitiesCount, minLength, manifest.Length)
+		if !options.Lenient {
+			return nil, err
 		}
+		filePhar.Issues = append(filePhar.Issues, Issue{Kind: IssueSizeMismatch, Detail: err.Error()})
 	}
 
-	for range manifest.EntitiesCount {
-		manifest, newOffset, err := ParseEntryManifest(r, offset)
+	now := time.Now()
+	progress := newProgressCounter(options.Progress, ProgressManifest, int64(manifest.EntitiesCount))
+	for file, err := range manifest.Entries(r, offset) {
 		if err != nil {
-			return nil, fmt.Errorf("cannot get file entry: %s", err)
+			return nil, err
+		}
+		if issues := nameIssues(file.Filename); len(issues) > 0 {
+			if options.RejectInvalidNames {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidName, issues[0])
+			}
+			filePhar.Issues = append(filePhar.Issues, issues...)
 		}
-		offset = newOffset
-		filePhar.Files = append(filePhar.Files, manifest)
+		if file.Filename, err = applyAbsolutePolicy(file.Filename, options.absolutePaths()); err != nil {
+			return nil, err
+		}
+		if options.CheckTimestamps {
+			filePhar.Issues = append(filePhar.Issues, timestampIssues(file, now)...)
+		}
+		filePhar.Files = append(filePhar.Files, file)
+		progress.add(1)
 	}
 
+	// Duplicates removed only after content checks, content offsets include all entries
+	files, issues, err := applyDuplicatePolicy(filePhar.Files, options.Duplicates)
+	if err != nil {
+		return nil, err
+	}
+	filePhar.Issues = append(filePhar.Issues, issues...)
+
+	var contentSize int64
 	for _, file := range filePhar.Files {
-		file.dataOffset = offset
-		offset += file.dataLen
-		if file.FileInfo().IsDir() {
-			continue
+		contentSize += file.dataLen
+	}
+
+	// Signature need at least flags and GBMB after content
+	contentEnd := manifest.ContentOffset() + contentSize
+	if manifest.IsSigned && contentEnd+8 > size {
+		err := newTruncatedError("signature", contentEnd+8, size, filePhar.Files, manifest.ContentOffset())
+		if !options.Lenient {
+			return nil, err
+		}
+		filePhar.Issues = append(filePhar.Issues, Issue{Kind: IssueTruncated, Detail: err.Error()})
+	} else if manifest.IsSigned {
+		if filePhar.Signature, err = getSignature(r, size, !options.Trusted, options.Progress); err != nil {
+			if err != ErrOpenssl {
+				return nil, err
+			}
+		} else {
+			filePhar.verified = !options.Trusted
 		}
+	}
 
-		f, err := file.Open()
-		if err != nil {
-			return nil, fmt.Errorf("cannot check CRC to %s: %s", file.Filename, err)
+	// Entries content must end before signature
+	available := size
+	if filePhar.Signature != nil {
+		available -= int64(len(filePhar.Signature.Hash)) + 8
+		if filePhar.Signature.Signature&SignatureOpenSSL != 0 {
+			available -= 4
 		}
-		hash := crc32.New(crc32.MakeTable(0xedb88320))
-		if _, err = io.Copy(hash, f); err != nil {
-			return nil, fmt.Errorf("fail copy %s content to crc32 hash: %s", file.Filename, err)
+	}
+	if contentEnd > available {
+		err := newTruncatedError("content", contentEnd, available, filePhar.Files, manifest.ContentOffset())
+		if !options.Lenient {
+			return nil, err
 		}
-		if hash.Sum32() != file.CRC {
-			return nil, fmt.Errorf("%s has bad CRC, expect: %d, recived: %d", file.Filename, file.CRC, hash.Sum32())
+		filePhar.Issues = append(filePhar.Issues, Issue{Kind: IssueTruncated, Detail: err.Error()})
+	} else if contentEnd < available && (filePhar.Signature != nil || !manifest.IsSigned) {
+		section := "end of file"
+		if filePhar.Signature != nil {
+			section = "signature"
 		}
+		detail := fmt.Sprintf("%d bytes between content end at %d and %s at %d", available-contentEnd, contentEnd, section, available)
+		filePhar.Issues = append(filePhar.Issues, Issue{Kind: IssueTrailingBytes, Detail: detail})
+	}
+
+	filePhar.Files = files
+	if options.SkipCRC || options.Trusted {
+		return filePhar, nil
+	}
+
+	for _, err := range verifyFiles(filePhar.Files, runtime.GOMAXPROCS(0), options.Progress) {
+		var crcErr *CRCError
+		if err == nil {
+			continue
+		} else if !options.ReportCRC || !errors.As(err, &crcErr) {
+			return nil, err
+		}
+		filePhar.Issues = append(filePhar.Issues, Issue{Kind: IssueBadCRC, Entry: crcErr.Filename, Detail: fmt.Sprintf("expect CRC %08x, got %08x", crcErr.Expected, crcErr.Got)})
 	}
 
 	return filePhar, nil
 }
+
+// verifyFiles check files CRC with up to workers goroutines,
+// returning errors of each file in manifest order
+func verifyFiles(files []*File, workers int, fn ProgressFunc) []error {
+	errs := make([]error, len(files))
+	indexes := make(chan int)
+
+	var total int64
+	for _, file := range files {
+		total += file.SizeUncompressed
+	}
+	progress := newProgressCounter(fn, ProgressCRC, total)
+
+	var wg sync.WaitGroup
+	for range max(min(workers, len(files)), 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				errs[index] = files[index].VerifyCRC()
+				progress.add(files[index].SizeUncompressed)
+			}
+		}()
+	}
+
+	for index := range files {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+	return errs
+}
+
+// VerifyCRC check decompressed file content with manifest CRC, returning [*CRCError] if
+// not match, to verify only needed entries of archives parsed with [ReaderOptions.Trusted]
+func (file *File) VerifyCRC() error {
+	if file.FileInfo().IsDir() {
+		return nil
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("cannot check CRC to %s: %w", file.Filename, err)
+	}
+	defer f.Close()
+
+	hash := crc32.NewIEEE()
+	if _, err = copyBuffer(hash, f); err != nil {
+		return fmt.Errorf("fail copy %s content to crc32 hash: %w", file.Filename, err)
+	}
+	if hash.Sum32() != file.CRC {
+		return &CRCError{Filename: file.Filename, Expected: file.CRC, Got: hash.Sum32()}
+	}
+	return nil
+}
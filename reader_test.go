@@ -1,8 +1,15 @@
 package phargo
 
 import (
+	"bytes"
+	"embed"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -50,7 +57,7 @@ func TestSimple(t *testing.T) {
 		return
 	}
 
-	if string(file.Menifest.Metadata) != "a:1:{s:1:\"a\";i:123;}" {
+	if string(file.Manifest.Metadata) != "a:1:{s:1:\"a\";i:123;}" {
 		t.Error("Wrong metadata")
 		return
 	}
@@ -63,8 +70,8 @@ func TestBadHash(t *testing.T) {
 		return
 	}
 
-	if _, err = NewReaderFromFile(osFile); err == nil {
-		t.Error("Should get error")
+	if _, err = NewReaderFromFile(osFile); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Should get ErrInvalidSignature, got %v", err)
 		return
 	}
 }
@@ -85,3 +92,305 @@ func TestAllPhars(t *testing.T) {
 		}
 	}
 }
+
+func TestBadCRC(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "a.txt", data: []byte("AAAA")},
+		testEntry{name: "b.txt", data: []byte("BBBB"), crc: 1},
+	)
+
+	_, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if !errors.Is(err, ErrBadCRC) {
+		t.Errorf("Should get ErrBadCRC, got %v", err)
+		return
+	} else if !strings.Contains(err.Error(), "b.txt") {
+		t.Errorf("Error should name b.txt, got %s", err)
+		return
+	}
+
+	var crcErr *CRCError
+	if !errors.As(err, &crcErr) || crcErr.Filename != "b.txt" || crcErr.Expected != 1 {
+		t.Errorf("Should get CRCError to b.txt, got %v", err)
+		return
+	}
+
+	data = buildPhar(
+		testEntry{name: "a.txt", data: []byte("AAAA"), crc: 1},
+		testEntry{name: "b.txt", data: []byte("BBBB")},
+		testEntry{name: "c.txt", data: []byte("CCCC"), crc: 1},
+	)
+	pharInfo, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{ReportCRC: true})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(pharInfo.Issues) != 2 || pharInfo.Issues[0].Entry != "a.txt" || pharInfo.Issues[1].Entry != "c.txt" {
+		t.Errorf("Should report a.txt and c.txt, got %v", pharInfo.Issues)
+		return
+	}
+
+	// Trusted archive verify only requested entries
+	if pharInfo, err = NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{Trusted: true}); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if err := pharInfo.Files[1].VerifyCRC(); err != nil {
+		t.Error("b.txt should have good CRC, got", err)
+		return
+	} else if err := pharInfo.Files[2].VerifyCRC(); !errors.As(err, &crcErr) || crcErr.Filename != "c.txt" {
+		t.Errorf("Should get CRCError to c.txt, got %v", err)
+		return
+	}
+}
+
+func TestMmap(t *testing.T) {
+	mmapFile, err := OpenMmap("./testdata/metadata_dir_sha256.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer mmapFile.Close()
+
+	file, err := NewReaderFromMmap(mmapFile)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(file.Files) != 4 {
+		t.Error("Not 4 files")
+		return
+	}
+}
+
+func TestTrusted(t *testing.T) {
+	osFile, err := os.Open("./testdata/PocketMine-MP_1.4.1.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+	stat, _ := osFile.Stat()
+
+	file, err := NewReaderWithOptions(osFile, stat.Size(), ReaderOptions{Trusted: true})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	// Read only manifest and signature at end of file
+	contentOffset := file.Manifest.ContentOffset()
+	r := &rangeReaderAt{ReaderAt: osFile, start: contentOffset, end: stat.Size() - int64(len(file.Signature.Hash)) - 8}
+	if _, err := NewReaderWithOptions(r, stat.Size(), ReaderOptions{Trusted: true}); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if r.touched {
+		t.Error("Trusted mode read content section")
+		return
+	}
+}
+
+// rangeReaderAt record reads touching [start, end) range
+type rangeReaderAt struct {
+	io.ReaderAt
+	start, end int64
+	touched    bool
+}
+
+func (r *rangeReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < r.end && off+int64(len(p)) > r.start {
+		r.touched = true
+	}
+	return r.ReaderAt.ReadAt(p, off)
+}
+
+func TestGzipEntry(t *testing.T) {
+	// PHP write gzip entries as raw deflate stream, without gzip header
+	osFile, err := os.Open("./testdata/gz.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+
+	file, err := NewReaderFromFile(osFile)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(file.Files) != 1 || file.Files[0].Flags&EntryCompressedGzip == 0 {
+		t.Errorf("Should get one gzip entry, got %v", file.Files)
+		return
+	}
+	if content, err := fs.ReadFile(file, "ABCD"); err != nil || string(content) != "DATADATADATADATA" {
+		t.Errorf("Wrong ABCD content: %q, %v", content, err)
+		return
+	}
+}
+
+func BenchmarkOpenGzip(b *testing.B) {
+	data, err := os.ReadFile("./testdata/gz.phar")
+	if err != nil {
+		b.Skip(err)
+		return
+	}
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		f, err := file.Files[0].Open()
+		if err != nil {
+			b.Fatal(err)
+		} else if _, err := io.Copy(io.Discard, f); err != nil {
+			b.Fatal(err)
+		}
+		f.Close()
+	}
+}
+
+func TestReaderLimits(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "a.txt", data: []byte("AAAA")},
+		testEntry{name: "long/name/to/file.txt", data: []byte("BBBB")},
+	)
+
+	for name, options := range map[string]ReaderOptions{
+		"entries":  {MaxEntries: 1},
+		"filename": {MaxFilenameLen: 8},
+		"manifest": {MaxManifestLen: 32},
+	} {
+		if _, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), options); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("Should get ErrLimitExceeded with %s limit, got %v", name, err)
+			return
+		}
+	}
+
+	options := ReaderOptions{MaxEntries: 2, MaxFilenameLen: 32, MaxManifestLen: 1024, MaxAliasLen: 1, MaxMetadataLen: 1}
+	if _, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), options); err != nil {
+		t.Error("Got error", err)
+		return
+	}
+}
+
+func TestReaderSizeMismatch(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "a.txt", data: []byte("AAAA")},
+		testEntry{name: "b.txt", data: []byte("BBBB")},
+	)
+	stubLen := len("<?php __HALT_COMPILER(); ?>\r\n")
+
+	manyEntries := bytes.Clone(data)
+	binary.LittleEndian.PutUint32(manyEntries[stubLen+4:], 1000)
+
+	if _, err := NewReaderWithOptions(bytes.NewReader(manyEntries), int64(len(manyEntries)), ReaderOptions{}); !errors.Is(err, ErrBadManifest) {
+		t.Errorf("Should get ErrBadManifest with bad entries count, got %v", err)
+		return
+	}
+
+	for name, data := range map[string][]byte{
+		"manifest": data[:stubLen+20],
+		"content":  data[:len(data)-2],
+	} {
+		var truncated *TruncatedError
+		if _, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{}); !errors.Is(err, ErrTruncated) || !errors.As(err, &truncated) {
+			t.Errorf("Should get ErrTruncated with truncated %s, got %v", name, err)
+			return
+		} else if truncated.Section != name || truncated.Available >= truncated.Expected {
+			t.Errorf("Bad truncated error: %+v", truncated)
+			return
+		} else if name == "content" && (truncated.LastEntry == nil || truncated.LastEntry.Filename != "a.txt") {
+			t.Errorf("Last entry should be a.txt, got %v", truncated.LastEntry)
+			return
+		}
+	}
+
+	truncated := data[:len(data)-2]
+	pharInfo, err := NewReaderWithOptions(bytes.NewReader(truncated), int64(len(truncated)), ReaderOptions{Lenient: true, SkipCRC: true})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(pharInfo.Issues) != 1 || pharInfo.Issues[0].Kind != IssueTruncated {
+		t.Errorf("Should report truncated content, got %v", pharInfo.Issues)
+		return
+	}
+}
+
+func TestReaderDuplicates(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "a.txt", data: []byte("first")},
+		testEntry{name: "b.txt", data: []byte("BBBB")},
+		testEntry{name: "a.txt", data: []byte("last")},
+	)
+
+	if _, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{Duplicates: DuplicatesReject}); !errors.Is(err, ErrDuplicateEntry) {
+		t.Errorf("Should get ErrDuplicateEntry, got %v", err)
+		return
+	}
+
+	for policy, expected := range map[DuplicatePolicy][]string{
+		DuplicatesKeepAll:  {"a.txt", "b.txt", "a.txt"},
+		DuplicatesKeepLast: {"b.txt", "a.txt"},
+	} {
+		pharInfo, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{Duplicates: policy})
+		if err != nil {
+			t.Error("Got error", err)
+			return
+		} else if len(pharInfo.Issues) != 1 || pharInfo.Issues[0].Kind != IssueDuplicate {
+			t.Errorf("Should report duplicate, got %v", pharInfo.Issues)
+			return
+		}
+
+		var names []string
+		for _, file := range pharInfo.Files {
+			names = append(names, file.Filename)
+		}
+		if !slices.Equal(names, expected) {
+			t.Errorf("Policy %d should keep %v, got %v", policy, expected, names)
+			return
+		}
+	}
+
+	pharInfo, _ := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{Duplicates: DuplicatesKeepLast})
+	if content, err := fs.ReadFile(pharInfo, "a.txt"); err != nil || string(content) != "last" {
+		t.Errorf("Should read last a.txt, got %q, %v", content, err)
+		return
+	}
+}
+
+func TestSignatureSize(t *testing.T) {
+	for _, flag := range []SignatureFlag{SignatureMD5, SignatureSHA1, SignatureSHA256, SignatureSHA512, SignatureOpenSSL} {
+		data := binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, 16), uint32(flag)) // OpenSSL length or hash start
+		data = append(data, "GBMB"...)
+		if _, err := GetSignature(bytes.NewReader(data), int64(len(data))); !errors.Is(err, ErrSignatureSize) {
+			t.Errorf("Should get ErrSignatureSize to %s, got %v", flag, err)
+			return
+		}
+	}
+
+	if _, err := GetSignature(bytes.NewReader([]byte("GBMB")), 4); !errors.Is(err, ErrSignatureSize) {
+		t.Errorf("Should get ErrSignatureSize to 4 bytes archive, got %v", err)
+		return
+	}
+}
+
+//go:embed testdata/simple.phar
+var embedTestdata embed.FS
+
+// readOnlyFS hide [io.ReaderAt] of files
+type readOnlyFS struct{ fs.FS }
+
+func (fsys readOnlyFS) Open(name string) (fs.File, error) {
+	file, err := fsys.FS.Open(name)
+	return struct{ fs.File }{file}, err
+}
+
+func TestOpenFS(t *testing.T) {
+	for name, fsys := range map[string]fs.FS{"embed": embedTestdata, "buffered": readOnlyFS{embedTestdata}} {
+		pharInfo, err := OpenFS(fsys, "testdata/simple.phar")
+		if err != nil {
+			t.Errorf("Got error with %s: %s", name, err)
+			return
+		} else if len(pharInfo.Files) == 0 {
+			t.Errorf("No files with %s", name)
+			return
+		}
+	}
+}
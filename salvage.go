@@ -0,0 +1,173 @@
+package phargo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Biggest entry name accepted as plausible while salvaging
+const salvageMaxFilenameLen = 4096
+
+// SalvageRegion is archive region [Salvage] can't recover
+type SalvageRegion struct {
+	Offset int64
+	Length int64
+	Reason string
+}
+
+// SalvageResult is entries recovered from damaged archive
+type SalvageResult struct {
+	Files       []*File         // Entries with content matching manifest CRC
+	Unrecovered []SalvageRegion // Manifest and content regions not recovered, in offset order
+}
+
+// Salvage scan damaged archive for plausible entry headers and return entries
+// with content still matching CRC, reporting regions it can't recover.
+//
+// Only stub with __HALT_COMPILER is required, manifest fields, entries headers
+// and content may be damaged. Entries after lost headers are usually unrecovered,
+// as their content offset depend on sizes of all previous entries.
+func Salvage(r io.ReaderAt, size int64) (*SalvageResult, error) {
+	offset, err := getOffset(r, stubScanChunkSize, haltCompiler)
+	if err != nil {
+		return nil, err
+	}
+
+	var lengthBuff [4]byte
+	if _, err := r.ReadAt(lengthBuff[:], offset); err != nil {
+		return nil, &TruncatedError{Section: "manifest", Expected: offset + 4, Available: size}
+	}
+
+	// Scan declared manifest if it fit in archive, else whole archive until headers stop
+	manifestEnd, knownEnd := offset+4+int64(binary.LittleEndian.Uint32(lengthBuff[:])), true
+	if manifestEnd > size {
+		manifestEnd, knownEnd = size, false
+	}
+	block, err := readAt(r, offset+4, manifestEnd-offset-4)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read manifest: %w", err)
+	}
+	raw := &memReaderAt{data: block, base: offset + 4}
+
+	result := &SalvageResult{}
+	scan := salvageEntriesStart(raw, offset+4, manifestEnd)
+	gapStart := int64(-1)
+	var files []*File
+	for scan < manifestEnd {
+		file, next, ok := salvageEntry(raw, scan, manifestEnd, size)
+		if !ok {
+			if !knownEnd && len(files) > 0 {
+				break
+			} else if gapStart < 0 {
+				gapStart = scan
+			}
+			scan++
+			continue
+		}
+
+		if gapStart >= 0 {
+			result.Unrecovered = append(result.Unrecovered, SalvageRegion{Offset: gapStart, Length: scan - gapStart, Reason: "no entry header"})
+			gapStart = -1
+		}
+		files = append(files, file)
+		scan = next
+	}
+	if gapStart >= 0 {
+		result.Unrecovered = append(result.Unrecovered, SalvageRegion{Offset: gapStart, Length: scan - gapStart, Reason: "no entry header"})
+	}
+	if len(files) == 0 {
+		return result, fmt.Errorf("%w: no entry header found", ErrBadManifest)
+	}
+
+	// Content start after manifest, or after last header when manifest length is damaged
+	dataOffset := manifestEnd
+	if !knownEnd {
+		dataOffset = scan
+	}
+	for _, file := range files {
+		file.metadataOpen, file.dataOffset = r, dataOffset
+		dataOffset += file.dataLen
+
+		if file.dataOffset+file.dataLen > size {
+			result.Unrecovered = append(result.Unrecovered, SalvageRegion{Offset: file.dataOffset, Length: max(size-file.dataOffset, 0), Reason: file.Filename + " is truncated"})
+		} else if err := file.VerifyCRC(); err != nil {
+			result.Unrecovered = append(result.Unrecovered, SalvageRegion{Offset: file.dataOffset, Length: file.dataLen, Reason: err.Error()})
+		} else {
+			result.Files = append(result.Files, file)
+		}
+	}
+	return result, nil
+}
+
+// salvageEntriesStart return first entry offset if alias and metadata lengths
+// fit in manifest, else offset after fixed archive fields
+func salvageEntriesStart(raw *memReaderAt, offset, manifestEnd int64) int64 {
+	offset += 10 // Entries count, API version and flags
+	var lengthBuff [4]byte
+	for range 2 { // Alias and metadata
+		if _, err := raw.ReadAt(lengthBuff[:], offset); err != nil {
+			return min(offset, manifestEnd)
+		}
+		next := offset + 4 + int64(binary.LittleEndian.Uint32(lengthBuff[:]))
+		if next > manifestEnd {
+			return offset + 4
+		}
+		offset = next
+	}
+	return offset
+}
+
+// salvageCandidate check fixed entry header fields at offset without allocating, so
+// full parse run only at plausible offsets: name length fit, name start with printable
+// byte, flags are known, stored sizes match and lengths fit in archive
+func salvageCandidate(raw *memReaderAt, offset, manifestEnd, size int64) bool {
+	le, pos := binary.LittleEndian, offset-raw.base
+	if pos < 0 || pos+4 > int64(len(raw.data)) {
+		return false
+	}
+	nameLen := int64(le.Uint32(raw.data[pos:]))
+	if nameLen == 0 || nameLen > salvageMaxFilenameLen || offset+4+nameLen+24 > manifestEnd || pos+4+nameLen+24 > int64(len(raw.data)) {
+		return false
+	} else if c := raw.data[pos+4]; c < 0x20 || c == 0x7f {
+		return false
+	}
+
+	fields := raw.data[pos+4+nameLen:]
+	uncompressed, compressed := int64(le.Uint32(fields[0:4])), int64(le.Uint32(fields[8:12]))
+	flags, metaLen := EntryFlags(le.Uint32(fields[16:20])), int64(le.Uint32(fields[20:24]))
+	switch {
+	case flags>>16 != 0, metaLen > manifestEnd-offset:
+		return false
+	case flags.Compression() == EntryCompressedNone:
+		return (compressed == uncompressed || compressed == 0) && uncompressed <= size
+	case flags.Compression() == EntryCompressedGzip, flags.Compression() == EntryCompressedBzip2:
+		return compressed <= size
+	}
+	return false
+}
+
+// salvageEntry parse entry header at offset, reporting if it is plausible:
+// name has no issues, flags are known and sizes fit in archive
+func salvageEntry(raw *memReaderAt, offset, manifestEnd, size int64) (*File, int64, bool) {
+	if !salvageCandidate(raw, offset, manifestEnd, size) {
+		return nil, offset, false
+	}
+	options := ReaderOptions{MaxFilenameLen: salvageMaxFilenameLen, MaxMetadataLen: manifestEnd - offset}
+	file, next, err := parseEntryManifest(raw, offset, options)
+	switch {
+	case err != nil, next > manifestEnd:
+		return nil, offset, false
+	case file.Filename == "." || len(nameIssues(file.Filename)) > 0:
+		return nil, offset, false
+	case file.Flags>>16 != 0:
+		return nil, offset, false
+	case file.Flags.Compression() != EntryCompressedNone && file.Flags.Compression() != EntryCompressedGzip && file.Flags.Compression() != EntryCompressedBzip2:
+		return nil, offset, false
+	case file.Flags.Compression() == EntryCompressedNone && file.SizeCompressed != file.SizeUncompressed:
+		return nil, offset, false
+	case file.dataLen > size:
+		return nil, offset, false
+	}
+	return file, next, true
+}
@@ -0,0 +1,59 @@
+package phargo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+func TestSalvage(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "a.txt", data: []byte("AAAA")},
+		testEntry{name: "b.txt", data: []byte("BBBB")},
+		testEntry{name: "c.txt", data: []byte("CCCC")},
+	)
+	stubLen := len("<?php __HALT_COMPILER(); ?>\r\n")
+
+	// Damage entries count, b.txt content and manifest length
+	binary.LittleEndian.PutUint32(data[stubLen+4:], 0xFFFFFFFF)
+	data[len(data)-6] = 'X'
+	for name, data := range map[string][]byte{
+		"content":  data,
+		"manifest": append(binary.LittleEndian.AppendUint32(bytes.Clone(data[:stubLen]), 0xFFFFFFF), data[stubLen+4:]...),
+	} {
+		result, err := Salvage(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Errorf("Salvage with damaged %s: %s", name, err)
+			return
+		} else if len(result.Files) != 2 || result.Files[0].Filename != "a.txt" || result.Files[1].Filename != "c.txt" {
+			t.Errorf("Should recover a.txt and c.txt with damaged %s, got %v", name, result.Files)
+			return
+		} else if len(result.Unrecovered) != 1 || result.Unrecovered[0].Length != 4 {
+			t.Errorf("Should report b.txt content as unrecovered with damaged %s, got %v", name, result.Unrecovered)
+			return
+		}
+	}
+}
+
+func TestSalvageGarbage(t *testing.T) {
+	// Archive with 4MiB random bytes where manifest was, all offsets scanned
+	garbage := make([]byte, 4<<20)
+	rand.New(rand.NewSource(1)).Read(garbage)
+	data := append([]byte("<?php __HALT_COMPILER(); ?>\r\n"), binary.LittleEndian.AppendUint32(nil, 0xFFFFFFF)...)
+	data = append(data, garbage...)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	_, err := Salvage(bytes.NewReader(data), int64(len(data)))
+	runtime.ReadMemStats(&after)
+	if !errors.Is(err, ErrBadManifest) {
+		t.Errorf("Should get ErrBadManifest, got %v", err)
+		return
+	} else if mallocs := after.Mallocs - before.Mallocs; mallocs > 1024 {
+		t.Errorf("Salvage scan of garbage made %d allocations", mallocs)
+		return
+	}
+}
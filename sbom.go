@@ -0,0 +1,80 @@
+package phargo
+
+import (
+	"net/url"
+	"strings"
+)
+
+// CycloneDX is CycloneDX 1.5 json BOM, with fields filled by [Phar.CycloneDX]
+type CycloneDX struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    *CycloneDXMetadata   `json:"metadata,omitempty"`
+	Components  []CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXMetadata describe BOM subject
+type CycloneDXMetadata struct {
+	Component *CycloneDXComponent `json:"component,omitempty"`
+}
+
+// CycloneDXComponent is package or application in BOM
+type CycloneDXComponent struct {
+	Type     string             `json:"type"`
+	BOMRef   string             `json:"bom-ref,omitempty"`
+	Name     string             `json:"name"`
+	Group    string             `json:"group,omitempty"`
+	Version  string             `json:"version,omitempty"`
+	PURL     string             `json:"purl,omitempty"`
+	Scope    string             `json:"scope,omitempty"`
+	Licenses []CycloneDXLicense `json:"licenses,omitempty"`
+}
+
+// CycloneDXLicense is SPDX license of component
+type CycloneDXLicense struct {
+	License CycloneDXLicenseID `json:"license"`
+}
+
+// CycloneDXLicenseID is license id in CycloneDX layout
+type CycloneDXLicenseID struct {
+	ID string `json:"id"`
+}
+
+// CycloneDX return BOM of PHP packages from [Phar.ComposerPackages], with name
+// as main component. Dev packages have "excluded" scope.
+func (phar *Phar) CycloneDX(name string) (*CycloneDX, error) {
+	packages, err := phar.ComposerPackages()
+	if err != nil {
+		return nil, err
+	}
+
+	bom := &CycloneDX{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata:    &CycloneDXMetadata{Component: &CycloneDXComponent{Type: "application", Name: name}},
+		Components:  make([]CycloneDXComponent, 0, len(packages)),
+	}
+	for _, pkg := range packages {
+		group, pkgName, ok := strings.Cut(pkg.Name, "/")
+		if !ok {
+			group, pkgName = "", pkg.Name
+		}
+
+		// Package URL: pkg:composer/vendor/name@version
+		purl := "pkg:composer/" + pkg.Name
+		if pkg.Version != "" {
+			purl += "@" + url.PathEscape(pkg.Version)
+		}
+		component := CycloneDXComponent{Type: "library", BOMRef: purl, Name: pkgName, Group: group, Version: pkg.Version, PURL: purl}
+		if pkg.Dev {
+			component.Scope = "excluded"
+		}
+		for _, license := range pkg.License {
+			component.Licenses = append(component.Licenses, CycloneDXLicense{CycloneDXLicenseID{license}})
+		}
+		bom.Components = append(bom.Components, component)
+	}
+	return bom, nil
+}
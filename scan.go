@@ -0,0 +1,111 @@
+package phargo
+
+import (
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Stub bytes read by [Sniff] looking for __HALT_COMPILER
+const sniffStubSize = 1 << 20
+
+// Sniff report if content look like phar archive: stub with __HALT_COMPILER in
+// first 1MiB, followed by manifest length fitting in size
+func Sniff(r io.ReaderAt, size int64) bool {
+	offset, err := getOffset(io.NewSectionReader(r, 0, min(size, sniffStubSize)), stubScanChunkSize, haltCompiler)
+	if err != nil {
+		return false
+	}
+
+	var lengthBuff [4]byte
+	if _, err := r.ReadAt(lengthBuff[:], offset); err != nil {
+		return false
+	}
+	length := int64(binary.LittleEndian.Uint32(lengthBuff[:]))
+	return length >= 14 && offset+4+length <= size
+}
+
+// InventoryItem is phar archive found by [ScanDir]
+type InventoryItem struct {
+	Path      string
+	Size      int64
+	Entries   int
+	Signature string // Signature algorithm, or "unsigned"
+	Alias     string
+	Stub      StubInfo
+	Issues    []Issue
+	Error     string // Parse error, empty if archive is valid
+}
+
+// Inventory is phar archives found by [ScanDir]
+type Inventory struct {
+	Root    string
+	Scanned int // Regular files sniffed
+	Skipped int // Files and folders not readable
+	Phars   []InventoryItem
+}
+
+// ScanDir walk root, like host or container rootfs, identifying phar archives by content
+// with [Sniff] instead of extension, and parse each with options.
+// Symlinks are not followed and unreadable files are counted as skipped.
+func ScanDir(root string, options ReaderOptions) (*Inventory, error) {
+	inventory := &Inventory{Root: root}
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			inventory.Skipped++
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		} else if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		item, err := scanFile(path, options)
+		if err != nil {
+			inventory.Skipped++
+			return nil
+		}
+		inventory.Scanned++
+		if item != nil {
+			inventory.Phars = append(inventory.Phars, *item)
+		}
+		return nil
+	})
+	return inventory, err
+}
+
+// scanFile sniff and parse file, returning nil item if file is not phar
+func scanFile(path string, options ReaderOptions) (*InventoryItem, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, err
+	} else if !Sniff(file, stat.Size()) {
+		return nil, nil
+	}
+
+	item := &InventoryItem{Path: path, Size: stat.Size()}
+	pharInfo, err := NewReaderWithOptions(file, stat.Size(), options)
+	if err != nil {
+		item.Error = err.Error()
+		return item, nil
+	}
+
+	item.Entries, item.Alias, item.Stub, item.Issues = len(pharInfo.Files), string(pharInfo.Manifest.Alias), pharInfo.Stub, pharInfo.Issues
+	item.Signature = "unsigned"
+	if pharInfo.Signature != nil {
+		item.Signature = pharInfo.Signature.Signature.String()
+	}
+	return item, nil
+}
@@ -0,0 +1,53 @@
+package phargo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanDir(t *testing.T) {
+	dir := t.TempDir()
+	simple, err := os.ReadFile("./testdata/simple.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+
+	for name, data := range map[string][]byte{
+		"bin/tool":          simple,
+		"notes.txt":         []byte("__HALT_COMPILER(); ?> is not enough"),
+		"lib/bad.phar":      buildPhar(testEntry{name: "a.txt", data: []byte("AAAA"), crc: 1}),
+		"lib/empty.phar":    {},
+		"vendor/readme.php": []byte("<?php echo 1;"),
+	} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755); err != nil {
+			t.Error(err)
+			return
+		} else if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	inventory, err := ScanDir(dir, ReaderOptions{})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if inventory.Scanned != 5 || len(inventory.Phars) != 2 {
+		t.Errorf("Should scan 5 files and find 2 phars, got %d and %+v", inventory.Scanned, inventory.Phars)
+		return
+	}
+
+	items := map[string]InventoryItem{}
+	for _, item := range inventory.Phars {
+		items[filepath.ToSlash(item.Path[len(dir)+1:])] = item
+	}
+	if item := items["bin/tool"]; item.Error != "" || item.Entries == 0 || item.Signature != "sha1" {
+		t.Errorf("Wrong bin/tool item: %+v", item)
+		return
+	} else if item := items["lib/bad.phar"]; item.Error == "" {
+		t.Errorf("lib/bad.phar should have error: %+v", item)
+		return
+	}
+}
@@ -28,9 +28,18 @@ var (
 	pharSignatureLenLen  = 4
 	pharMaxSignatureLen  = 8 * 1024
 
-	ErrOpenssl          = errors.New("openssl is disabled in this implementation")
+	ErrOpenssl          = errors.New("openssl signature needs archive public key, check it with VerifyOpenSSLSignature")
 	ErrInvalidSignature = errors.New("invalid signature")
 	ErrGBMB             = errors.New("can't find GBMB constant at the end")
+	ErrSignatureSize    = errors.New("archive too small for declared signature")
+
+	// signatureHash is hash of signatures checked without OpenSSL
+	signatureHash = map[SignatureFlag]func() hash.Hash{
+		SignatureMD5:    md5.New,
+		SignatureSHA1:   sha1.New,
+		SignatureSHA256: sha256.New,
+		SignatureSHA512: sha512.New,
+	}
 
 	sigName = map[SignatureFlag]string{
 		SignatureMD5:           "md5",
@@ -68,8 +77,18 @@ type Signature struct {
 //
 // PHP Docs: https://www.php.net/manual/en/phar.fileformat.signature.php
 //
-// Important Golang not support have in std openssl module, and return [ErrOpenssl] if presence of openssl signature
+// OpenSSL signatures need archive public key, they are returned with [ErrOpenssl] and
+// checked by [VerifyOpenSSLSignature]
 func GetSignature(r io.ReaderAt, size int64) (*Signature, error) {
+	return getSignature(r, size, true, nil)
+}
+
+// getSignature read signature from archive end, if verify is false archive content is not hashed
+func getSignature(r io.ReaderAt, size int64, verify bool, fn ProgressFunc) (*Signature, error) {
+	if size < int64(pharSignatureStubLen) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrSignatureSize, size)
+	}
+
 	bin := make([]byte, 8)
 	_, err := r.ReadAt(bin, size-8)
 	if err != nil {
@@ -84,72 +103,53 @@ func GetSignature(r io.ReaderAt, size int64) (*Signature, error) {
 		return nil, ErrGBMB
 	}
 
+	// Hash length is fixed by algorithm, OpenSSL signatures store length before flags
 	var hashCalculator hash.Hash
+	hashEnd := size - int64(pharSignatureStubLen)
+	hashLen := int64(0)
 	switch newSignature.Signature {
-	case SignatureMD5:
-		hashCalculator = md5.New()
-		newSignature.Hash = make([]byte, 16)
-		if _, err := r.ReadAt(newSignature.Hash, size-24); err != nil {
-			return nil, fmt.Errorf("cannot get md5 hash: %s", err)
-		}
-	case SignatureSHA1:
-		hashCalculator = sha1.New()
-		newSignature.Hash = make([]byte, 20)
-		if _, err := r.ReadAt(newSignature.Hash, size-28); err != nil {
-			return nil, fmt.Errorf("cannot get sha1 hash: %s", err)
-		}
-	case SignatureSHA256:
-		hashCalculator = sha256.New()
-		newSignature.Hash = make([]byte, 32)
-		if _, err := r.ReadAt(newSignature.Hash, size-40); err != nil {
-			return nil, fmt.Errorf("cannot get sha256 hash: %s", err)
-		}
-	case SignatureSHA512:
-		hashCalculator = sha512.New()
-		newSignature.Hash = make([]byte, 64)
-		if _, err := r.ReadAt(newSignature.Hash, size-72); err != nil {
-			return nil, fmt.Errorf("cannot get sha512 hash: %s", err)
-		}
+	case SignatureMD5, SignatureSHA1, SignatureSHA256, SignatureSHA512:
+		hashCalculator = signatureHash[newSignature.Signature]()
+		hashLen = int64(hashCalculator.Size())
 	case SignatureOpenSSL, SignatureOpenSSLSha256, SignatureOpenSSLSha512:
-		lenOffset := size - int64(pharSignatureStubLen) - int64(pharSignatureLenLen)
-		if lenOffset < 0 {
-			return nil, fmt.Errorf("negative offset")
+		if hashEnd -= int64(pharSignatureLenLen); hashEnd < 0 {
+			return nil, fmt.Errorf("%w: %d bytes to %s signature length", ErrSignatureSize, size, newSignature.Signature)
 		}
 		lenBuf := make([]byte, pharSignatureLenLen)
-		n, readErr := r.ReadAt(lenBuf, lenOffset)
-		if readErr != nil {
-			return nil, fmt.Errorf("reading signature length at offset %d: %v", lenOffset, readErr)
-		} else if n != pharSignatureLenLen {
-			return nil, fmt.Errorf("reading signature length at offset %d: expected %d bytes, got %d", lenOffset, pharSignatureLenLen, n)
+		if _, err := r.ReadAt(lenBuf, hashEnd); err != nil {
+			return nil, fmt.Errorf("reading signature length at offset %d: %w", hashEnd, err)
 		}
-
-		sigLen32 := binary.LittleEndian.Uint32(lenBuf)
-		if sigLen32 == 0 || sigLen32 > uint32(pharMaxSignatureLen) {
-			return nil, fmt.Errorf("invalid signature length %d (must be > 0 and <= %d)", sigLen32, pharMaxSignatureLen)
-		}
-		sigLen := int64(sigLen32)
-		sigOffset := size - int64(pharSignatureStubLen) - int64(pharSignatureLenLen) - sigLen
-		if sigOffset < 0 {
-			return nil, fmt.Errorf("calculated negative signature offset %d (size: %d, sigLen: %d)", sigOffset, size, sigLen)
+		hashLen = int64(binary.LittleEndian.Uint32(lenBuf))
+		if hashLen == 0 || hashLen > int64(pharMaxSignatureLen) {
+			return nil, fmt.Errorf("%w: length %d (must be > 0 and <= %d)", ErrInvalidSignature, hashLen, pharMaxSignatureLen)
 		}
+	default:
+		return nil, fmt.Errorf("%w: unknown flag %#x", ErrInvalidSignature, uint32(newSignature.Signature))
+	}
 
-		newSignature.Hash = make([]byte, sigLen)
-		n, readErr = r.ReadAt(newSignature.Hash, sigOffset)
-		if readErr != nil && readErr != io.EOF {
-			return nil, fmt.Errorf("reading signature data at offset %d (length %d): %v", sigOffset, sigLen, readErr)
-		} else if int64(n) != sigLen {
-			return nil, fmt.Errorf("reading signature data at offset %d: expected %d bytes, got %d", sigOffset, sigLen, n)
-		}
+	hashOffset := hashEnd - hashLen
+	if hashOffset < 0 {
+		return nil, fmt.Errorf("%w: %d bytes to %d bytes %s signature", ErrSignatureSize, size, hashLen, newSignature.Signature)
+	}
+	if newSignature.Hash, err = readAt(r, hashOffset, hashLen); err != nil {
+		return nil, fmt.Errorf("cannot get %s hash: %w", newSignature.Signature, err)
+	}
+
+	if hashCalculator == nil {
 		return newSignature, ErrOpenssl
-	default:
-		return nil, ErrInvalidSignature
+	} else if !verify {
+		return newSignature, nil
 	}
 
 	// Check hash is same
-	if _, err := io.CopyN(hashCalculator, newReaderFromReaderAt(r), size-int64(8+len(newSignature.Hash))); err != nil {
+	var w io.Writer = hashCalculator
+	if progress := newProgressCounter(fn, ProgressSignature, hashOffset); progress != nil {
+		w = io.MultiWriter(hashCalculator, progress)
+	}
+	if _, err := io.CopyN(w, newSectionReader(r, 0, hashOffset), hashOffset); err != nil {
 		return nil, err
 	} else if !bytes.Equal(newSignature.Hash, hashCalculator.Sum(nil)) {
-		return nil, ErrInvalidSignature
+		return nil, fmt.Errorf("%w: %s hash mismatch", ErrInvalidSignature, newSignature.Signature)
 	}
 
 	return newSignature, nil
@@ -0,0 +1,33 @@
+package phargo
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// SortKey select entry field used by [Phar.SortedFiles]
+type SortKey int
+
+const (
+	SortByName  SortKey = iota // Filename, byte order
+	SortBySize                 // Uncompressed size, larger first
+	SortByMTime                // Timestamp, newer first
+)
+
+// SortedFiles return copy of [Phar.Files] sorted by key, entries with equal key
+// keep manifest order, so result is same across runs and machines
+func (phar *Phar) SortedFiles(by SortKey) []*File {
+	files := slices.Clone(phar.Files)
+	slices.SortStableFunc(files, func(a, b *File) int {
+		switch by {
+		case SortBySize:
+			return cmp.Compare(b.SizeUncompressed, a.SizeUncompressed)
+		case SortByMTime:
+			return b.Timestamp.Compare(a.Timestamp)
+		default:
+			return strings.Compare(a.Filename, b.Filename)
+		}
+	})
+	return files
+}
@@ -0,0 +1,46 @@
+package phargo
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSortedFiles(t *testing.T) {
+	data := buildPhar(
+		testEntry{name: "c.txt", data: []byte("C")},
+		testEntry{name: "a.txt", data: []byte("AAA")},
+		testEntry{name: "b.txt", data: []byte("BBB")},
+	)
+	file, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	for index, name := range []string{"c.txt", "a.txt", "b.txt"} {
+		if file.Files[index].Filename != name {
+			t.Errorf("Files not in manifest order, %d is %s", index, file.Files[index].Filename)
+			return
+		}
+	}
+	file.Files[0].Timestamp = time.Unix(200, 0)
+	file.Files[1].Timestamp = time.Unix(100, 0)
+	file.Files[2].Timestamp = time.Unix(300, 0)
+
+	for key, want := range map[SortKey][]string{
+		SortByName:  {"a.txt", "b.txt", "c.txt"},
+		SortBySize:  {"a.txt", "b.txt", "c.txt"}, // a and b same size, manifest order
+		SortByMTime: {"b.txt", "c.txt", "a.txt"},
+	} {
+		sorted := file.SortedFiles(key)
+		for index, name := range want {
+			if sorted[index].Filename != name {
+				t.Errorf("Key %d: entry %d is %s, expect %s", key, index, sorted[index].Filename, name)
+				break
+			}
+		}
+	}
+	if file.Files[0].Filename != "c.txt" {
+		t.Error("SortedFiles changed Files order")
+	}
+}
@@ -0,0 +1,94 @@
+package phargo
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+const (
+	StubCustom  StubKind = iota // Stub written by archive builder
+	StubDefault                 // Stub from Phar::createDefaultStub
+	StubWebPhar                 // Custom stub calling Phar::webPhar
+)
+
+var stubKindName = map[StubKind]string{
+	StubCustom:  "custom",
+	StubDefault: "default",
+	StubWebPhar: "webphar",
+}
+
+// StubKind classify archive stub
+type StubKind int
+
+func (kind StubKind) String() string {
+	if str, ok := stubKindName[kind]; ok {
+		return str
+	}
+	return "unknown"
+}
+
+func (kind StubKind) MarshalText() (text []byte, err error) {
+	return []byte(kind.String()), nil
+}
+
+// StubInfo is stub details found by [ParseStub]
+type StubInfo struct {
+	Kind       StubKind
+	Alias      string // Phar::mapPhar argument, empty if not called or without alias
+	PHPVersion string // Minimum PHP version checked by stub, like "7.2.0"
+}
+
+var (
+	stubMapPhar      = regexp.MustCompile(`(?i)\bPhar::mapPhar\(\s*(?:'([^']*)'|"([^"]*)")\s*[,)]`)
+	stubVersionAfter = regexp.MustCompile(`(?i)version_compare\(\s*PHP_VERSION\s*,\s*['"]([^'"]+)['"]`)
+	stubVersionFirst = regexp.MustCompile(`(?i)version_compare\(\s*['"]([^'"]+)['"]\s*,\s*PHP_VERSION\b`)
+	stubVersionID    = regexp.MustCompile(`\bPHP_VERSION_ID\s*<=?\s*(\d{5,6})\b`)
+)
+
+// stubParseWindow is bytes read from stub start and end to [ParseStub], big stubs like
+// binary launchers are not loaded in memory, PHP code checked by ParseStub is near edges
+const stubParseWindow = 16 * 1024
+
+// readStub return stub of size bytes, or its first and last [stubParseWindow] bytes joined by newline if bigger
+func readStub(r io.ReaderAt, size int64) ([]byte, error) {
+	if size <= 2*stubParseWindow {
+		return readAt(r, 0, size)
+	}
+	head, err := readAt(r, 0, stubParseWindow)
+	if err != nil {
+		return nil, err
+	}
+	tail, err := readAt(r, size-stubParseWindow, stubParseWindow)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(head, '\n'), tail...), nil
+}
+
+// ParseStub classify stub and extract mapPhar alias and required PHP version
+func ParseStub(stub []byte) StubInfo {
+	var info StubInfo
+	switch {
+	case bytes.Contains(stub, []byte("class Extract_Phar")):
+		info.Kind = StubDefault
+	case bytes.Contains(bytes.ToLower(stub), []byte("phar::webphar(")):
+		info.Kind = StubWebPhar
+	}
+
+	if match := stubMapPhar.FindSubmatch(stub); match != nil {
+		info.Alias = string(match[1]) + string(match[2])
+	}
+
+	if match := stubVersionAfter.FindSubmatch(stub); match != nil {
+		info.PHPVersion = string(match[1])
+	} else if match := stubVersionFirst.FindSubmatch(stub); match != nil {
+		info.PHPVersion = string(match[1])
+	} else if match := stubVersionID.FindSubmatch(stub); match != nil {
+		// PHP_VERSION_ID is major*10000 + minor*100 + patch
+		id, _ := strconv.Atoi(string(match[1]))
+		info.PHPVersion = strconv.Itoa(id/10000) + "." + strconv.Itoa(id/100%100) + "." + strconv.Itoa(id%100)
+	}
+	return info
+}
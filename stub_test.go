@@ -0,0 +1,62 @@
+package phargo
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestParseStub(t *testing.T) {
+	for stub, expected := range map[string]StubInfo{
+		"<?php __HALT_COMPILER(); ?>": {},
+		"<?php Phar::mapPhar('app.phar'); require 'phar://app.phar/index.php'; __HALT_COMPILER(); ?>":                   {Alias: "app.phar"},
+		`<?php if (version_compare(PHP_VERSION, "7.2.0", "<")) { exit(1); } \Phar::mapPhar("tool"); __HALT_COMPILER();`: {Alias: "tool", PHPVersion: "7.2.0"},
+		"<?php if (PHP_VERSION_ID < 80100) exit(1); Phar::webPhar(); __HALT_COMPILER(); ?>":                             {Kind: StubWebPhar, PHPVersion: "8.1.0"},
+		"<?php class Extract_Phar {} Phar::webPhar(null, $web); __HALT_COMPILER(); ?>":                                  {Kind: StubDefault},
+	} {
+		if info := ParseStub([]byte(stub)); info != expected {
+			t.Errorf("Wrong info to %q: expect %+v, got %+v", stub, expected, info)
+			return
+		}
+	}
+}
+
+func TestPharStub(t *testing.T) {
+	osFile, err := os.Open("./testdata/simple.phar")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer osFile.Close()
+
+	pharInfo, err := NewReaderFromFile(osFile)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if pharInfo.Stub.Kind != StubDefault {
+		t.Errorf("Should detect default stub, got %s", pharInfo.Stub.Kind)
+		return
+	}
+}
+
+func TestBigStub(t *testing.T) {
+	// Launcher bytes between stub code are not loaded in memory
+	prefix := append([]byte("<?php Phar::mapPhar('big.phar'); ?>"), make([]byte, 8<<20)...)
+	data := append(prefix, buildPhar(testEntry{name: "a.txt", data: []byte("AAAA")})...)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	pharInfo, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	} else if pharInfo.Stub.Alias != "big.phar" {
+		t.Errorf("Wrong stub alias %q", pharInfo.Stub.Alias)
+		return
+	} else if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Allocated %d bytes to parse archive with 8MiB stub", allocated)
+		return
+	}
+}
@@ -0,0 +1,169 @@
+package phargo
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// Lexer states of [stubHaltCompiler]
+const (
+	lexHTML         = iota // Inline HTML, outside php tags
+	lexCode                // PHP code
+	lexString              // '...', "..." or `...` string
+	lexLineComment         // "//" or "#" comment
+	lexBlockComment        // "/* */" comment
+	lexHeredoc             // Heredoc or nowdoc body
+)
+
+// Biggest heredoc label checked, PHP has no limit but stubs use short ones
+const lexMaxLabel = 128
+
+// stubHaltCompiler tokenize first limit bytes of stub like PHP lexer, skipping inline HTML, strings and comments,
+// and return archive start after first __HALT_COMPILER statement in code.
+// Markers in string literals or comments are never matched.
+func stubHaltCompiler(r io.ReaderAt, limit int64) (int64, bool) {
+	br := bufio.NewReaderSize(io.NewSectionReader(r, 0, limit), stubScanChunkSize)
+	var offset int64
+	skip := func(n int) {
+		discarded, _ := br.Discard(n)
+		offset += int64(discarded)
+	}
+	// peek report if next bytes are token, ignoring ASCII case
+	peek := func(token string) bool {
+		next, _ := br.Peek(len(token))
+		return bytes.EqualFold(next, []byte(token))
+	}
+
+	state, prev := lexHTML, byte(' ')
+	var quote byte   // String delimiter
+	var label []byte // Heredoc label
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return 0, false
+		}
+		offset++
+
+		switch state {
+		case lexHTML:
+			if c == '<' && peek("?") {
+				skip(1)
+				if peek("php") {
+					skip(3)
+				} else if peek("=") {
+					skip(1)
+				}
+				state, prev = lexCode, ' '
+			}
+		case lexCode:
+			last := prev
+			prev = c
+			switch {
+			case c == '\'', c == '"', c == '`':
+				state, quote = lexString, c
+			case c == '#' && !peek("["), c == '/' && peek("/"):
+				state = lexLineComment
+			case c == '/' && peek("*"):
+				skip(1)
+				state = lexBlockComment
+			case c == '?' && peek(">"):
+				skip(1)
+				state = lexHTML
+			case c == '<' && peek("<<"):
+				skip(2)
+				if label = lexHeredocLabel(br, &offset); label != nil {
+					state = lexHeredoc
+					if lexHeredocEnd(br, &offset, label) {
+						state = lexCode
+					}
+				}
+			case c == '_' && !isIdentByte(last) && last != '$' && peek(string(haltCompiler[1:])):
+				skip(len(haltCompiler) - 1)
+				if next, _ := br.Peek(1); len(next) == 1 && isIdentByte(next[0]) {
+					continue // Longer identifier, like __HALT_COMPILER_X
+				} else if end, ok := haltCompilerEnd(r, offset); ok {
+					return end, true
+				}
+			}
+		case lexString:
+			if c == '\\' {
+				skip(1)
+			} else if c == quote {
+				state, prev = lexCode, ' '
+			}
+		case lexLineComment:
+			if c == '\n' {
+				state, prev = lexCode, ' '
+			} else if c == '?' && peek(">") {
+				skip(1)
+				state = lexHTML
+			}
+		case lexBlockComment:
+			if c == '*' && peek("/") {
+				skip(1)
+				state, prev = lexCode, ' '
+			}
+		case lexHeredoc:
+			if c == '\n' && lexHeredocEnd(br, &offset, label) {
+				state, prev = lexCode, ' '
+			}
+		}
+	}
+}
+
+// lexHeredocLabel read heredoc or nowdoc label after "<<<" until end of line,
+// returning nil if it is not valid label
+func lexHeredocLabel(br *bufio.Reader, offset *int64) []byte {
+	line, _ := br.Peek(lexMaxLabel + 4)
+	pos := 0
+	for pos < len(line) && (line[pos] == ' ' || line[pos] == '\t') {
+		pos++
+	}
+	var quote byte
+	if pos < len(line) && (line[pos] == '\'' || line[pos] == '"') {
+		quote = line[pos]
+		pos++
+	}
+
+	start := pos
+	for pos < len(line) && isIdentByte(line[pos]) {
+		pos++
+	}
+	if pos == start || (line[start] >= '0' && line[start] <= '9') {
+		return nil
+	}
+	label := bytes.Clone(line[start:pos])
+	if quote != 0 {
+		if pos >= len(line) || line[pos] != quote {
+			return nil
+		}
+		pos++
+	}
+	if pos >= len(line) || (line[pos] != '\n' && line[pos] != '\r') {
+		return nil
+	}
+
+	// Label line end with newline, body start at next line
+	discarded, _ := br.Discard(pos)
+	rest, _ := br.ReadBytes('\n')
+	*offset += int64(discarded + len(rest))
+	return label
+}
+
+// lexHeredocEnd consume closing label if line at reader start close heredoc
+func lexHeredocEnd(br *bufio.Reader, offset *int64, label []byte) bool {
+	line, _ := br.Peek(lexMaxLabel + len(label) + 1)
+	trimmed := bytes.TrimLeft(line, " \t")
+	if !bytes.HasPrefix(trimmed, label) || (len(trimmed) > len(label) && isIdentByte(trimmed[len(label)])) {
+		return false
+	}
+	consumed, _ := br.Discard(len(line) - len(trimmed) + len(label))
+	*offset += int64(consumed)
+	return true
+}
+
+// isIdentByte report if c can be part of PHP identifier
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,90 @@
+package phargo
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestStubHaltCompiler(t *testing.T) {
+	const real = "__HALT_COMPILER(); ?>\n"
+	for _, stub := range []string{
+		"<?php " + real,
+		"<?php echo '__HALT_COMPILER(); ?>'; " + real,
+		`<?php echo "\"__HALT_COMPILER(); ?>"; ` + real,
+		"<?php // __HALT_COMPILER();\n" + real,
+		"<?php # __HALT_COMPILER();\n" + real,
+		"<?php /* __HALT_COMPILER(); ?> */ " + real,
+		"<?php $a = <<<EOT\n__HALT_COMPILER(); ?>\nEOT;\n" + real,
+		"<?php $a = <<<'EOT'\n  __HALT_COMPILER();\n  EOT;\n" + real,
+		"__HALT_COMPILER(); ?>\n<?php " + real,
+		"<?php ?>__HALT_COMPILER();<?= 1 ?><?php " + real,
+		"<?php $__HALT_COMPILER(); __HALT_COMPILER_X(); " + real,
+		"#!/usr/bin/env php\n<?php\nPhar::mapPhar('a.phar');\n__halt_compiler(); ?>\n",
+	} {
+		offset, ok := stubHaltCompiler(bytes.NewReader([]byte(stub+"DATA")), math.MaxInt64)
+		if !ok {
+			t.Errorf("Should find __HALT_COMPILER in %q", stub)
+			return
+		} else if offset != int64(len(stub)) {
+			t.Errorf("Wrong offset in %q: expect %d, got %d", stub, len(stub), offset)
+			return
+		}
+	}
+
+	// Marker only in strings is not matched
+	stub := "<?php echo '" + real + "'; " + strings.Repeat("x", 10)
+	if _, ok := stubHaltCompiler(strings.NewReader(stub), math.MaxInt64); ok {
+		t.Errorf("Should not find __HALT_COMPILER in %q", stub)
+		return
+	}
+
+	data := append([]byte("<?php echo '__HALT_COMPILER(); ?>'; "), buildPhar(testEntry{name: "a.txt", data: []byte("AAAA")})...)
+	if pharInfo, err := NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if len(pharInfo.Files) != 1 || pharInfo.Files[0].Filename != "a.txt" {
+		t.Errorf("Should get a.txt, got %v", pharInfo.Files)
+		return
+	}
+}
+
+// bytesReaderAt count bytes read
+type bytesReaderAt struct {
+	io.ReaderAt
+	read int64
+}
+
+func (r *bytesReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.ReaderAt.ReadAt(p, off)
+	r.read += int64(n)
+	return n, err
+}
+
+func TestGetOffsetBounded(t *testing.T) {
+	const real = "__HALT_COMPILER(); ?>\n"
+	for _, stub := range []string{
+		"<?php echo '__HALT_COMPILER(); ?>'; " + real,
+		"__HALT_COMPILER(); ?>\n<?php " + real,
+		"#!/usr/bin/env php\n<?php\n__halt_compiler(); ?>\n",
+	} {
+		if offset, err := getOffset(strings.NewReader(stub+"DATA"), stubScanChunkSize, haltCompiler); err != nil || offset != int64(len(stub)) {
+			t.Errorf("Wrong offset in %q: expect %d, got %d, %v", stub, len(stub), offset, err)
+			return
+		}
+	}
+
+	// Binary launcher never lexed as code, marker is taken raw and archive after window is not read
+	launcher := append(bytes.Repeat([]byte{0x7f, 'E', 'L', 'F', '<', '?', '\''}, 1000), real...)
+	data := append(launcher, make([]byte, 4<<20)...)
+	r := &bytesReaderAt{ReaderAt: bytes.NewReader(data)}
+	if offset, err := getOffset(r, stubScanChunkSize, haltCompiler); err != nil || offset != int64(len(launcher)) {
+		t.Errorf("Wrong launcher offset: expect %d, got %d, %v", len(launcher), offset, err)
+		return
+	} else if limit := int64(len(launcher) + 2*stubLexWindow + 4*stubScanChunkSize); r.read > limit {
+		t.Errorf("Read %d bytes to find stub end, expect less than %d", r.read, limit)
+		return
+	}
+}
@@ -0,0 +1,69 @@
+package stubs
+
+import (
+	"path"
+	"strings"
+)
+
+// Phar::webPhar values to run entry as PHP, or show PHP source with highlight
+const (
+	MIMEPHP  = "Phar::PHP"
+	MIMEPHPS = "Phar::PHPS"
+)
+
+// webMIMETypes is extension to MIME type table used by [MIMETypes], fixed
+// so generated stubs not depend on host mime.types files
+var webMIMETypes = map[string]string{
+	"php":   MIMEPHP,
+	"phps":  MIMEPHPS,
+	"html":  "text/html",
+	"htm":   "text/html",
+	"css":   "text/css",
+	"js":    "text/javascript",
+	"mjs":   "text/javascript",
+	"json":  "application/json",
+	"map":   "application/json",
+	"xml":   "application/xml",
+	"txt":   "text/plain",
+	"md":    "text/markdown",
+	"csv":   "text/csv",
+	"svg":   "image/svg+xml",
+	"png":   "image/png",
+	"jpg":   "image/jpeg",
+	"jpeg":  "image/jpeg",
+	"gif":   "image/gif",
+	"webp":  "image/webp",
+	"avif":  "image/avif",
+	"ico":   "image/x-icon",
+	"woff":  "font/woff",
+	"woff2": "font/woff2",
+	"ttf":   "font/ttf",
+	"otf":   "font/otf",
+	"wasm":  "application/wasm",
+	"pdf":   "application/pdf",
+	"zip":   "application/zip",
+	"mp3":   "audio/mpeg",
+	"mp4":   "video/mp4",
+	"webm":  "video/webm",
+}
+
+// MIMETypes return Phar::webPhar MIME map to extensions of entries names, to
+// [WebOptions.MIMETypes]. Extensions not in table are skipped, webPhar serve them with its defaults.
+func MIMETypes(names []string) map[string]string {
+	types := map[string]string{}
+	for _, name := range names {
+		ext := strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+		if mimeType, ok := webMIMETypes[ext]; ok {
+			types[ext] = mimeType
+		}
+	}
+	return types
+}
+
+// phpMIMEValue quote MIME type, keeping Phar constants as is
+func phpMIMEValue(value string) string {
+	if value == MIMEPHP || value == MIMEPHPS {
+		return value
+	}
+	return phpString(value)
+}
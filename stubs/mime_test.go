@@ -0,0 +1,21 @@
+package stubs
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestMIMETypes(t *testing.T) {
+	types := MIMETypes([]string{"index.php", "assets/App.CSS", "assets/app.css", "LICENSE", "data.unknown", "docs/source.phps"})
+	expected := map[string]string{"php": MIMEPHP, "css": "text/css", "phps": MIMEPHPS}
+	if !maps.Equal(types, expected) {
+		t.Errorf("Wrong MIME map: %v", types)
+		return
+	}
+
+	if value := phpMIMEValue(MIMEPHP); value != "Phar::PHP" {
+		t.Errorf("Phar constant should not be quoted: %s", value)
+	} else if value := phpMIMEValue("text/css"); value != "'text/css'" {
+		t.Errorf("MIME type should be quoted: %s", value)
+	}
+}
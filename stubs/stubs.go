@@ -0,0 +1,119 @@
+// Package stubs render PHP stubs to phar archives from parameterized templates:
+// minimal CLI launcher, [Phar::webPhar] front controller and self-extracting stub.
+//
+// All stubs end with "__HALT_COMPILER(); ?>\r\n", ready to prepend to archive manifest.
+//
+// [Phar::webPhar]: https://www.php.net/manual/en/phar.webphar.php
+package stubs
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// ErrMissingEntry is returned when stub has no entry to run
+var ErrMissingEntry = errors.New("stub entry not set")
+
+// CLIOptions configure [CLI] stub
+type CLIOptions struct {
+	Alias         string // Phar::mapPhar alias, default "app.phar"
+	Entry         string // Entry required on run, like "bin/app.php"
+	MinPHPVersion string // Exit with error on older PHP, like "8.1.0", empty to not check
+}
+
+// WebOptions configure [Web] stub
+type WebOptions struct {
+	Alias    string // Phar::webPhar alias, default "app.phar"
+	Index    string // Entry served to folder requests and run from CLI, default "index.php"
+	NotFound string // Entry served to missing files, empty to PHP default 404 page
+
+	// MIMETypes is extension to MIME type map passed to webPhar, like [MIMETypes] result,
+	// [MIMEPHP] and [MIMEPHPS] values run or highlight entries
+	MIMETypes map[string]string
+}
+
+// SelfExtractOptions configure [SelfExtract] stub
+type SelfExtractOptions struct {
+	Dir   string // Folder files are extracted to, default temporary folder named by archive hash
+	Entry string // Entry required from extracted folder, empty to only extract
+}
+
+var (
+	templates = template.Must(template.New("stubs").Funcs(template.FuncMap{"php": phpString, "mime": phpMIMEValue}).Parse(stubTemplates))
+
+	stubTemplates = `{{define "cli"}}#!/usr/bin/env php
+<?php
+{{- if .MinPHPVersion}}
+if (version_compare(PHP_VERSION, {{php .MinPHPVersion}}, '<')) {
+    fwrite(STDERR, {{php (printf "PHP %s or newer is required, running " .MinPHPVersion)}} . PHP_VERSION . PHP_EOL);
+    exit(1);
+}
+{{- end}}
+Phar::mapPhar({{php .Alias}});
+require {{php (printf "phar://%s/%s" .Alias .Entry)}};
+__HALT_COMPILER(); ?>
+{{end}}
+
+{{- define "web"}}<?php
+Phar::webPhar({{php .Alias}}, {{php .Index}}, {{if .NotFound}}{{php .NotFound}}{{else}}null{{end}}
+{{- if .MIMETypes}}, [
+{{- range $ext, $type := .MIMETypes}}
+    {{php $ext}} => {{mime $type}},
+{{- end}}
+]{{end}});
+require 'phar://' . __FILE__ . {{php (printf "/%s" .Index)}};
+__HALT_COMPILER(); ?>
+{{end}}
+
+{{- define "selfextract"}}<?php
+$dir = {{if .Dir}}{{php .Dir}}{{else}}sys_get_temp_dir() . DIRECTORY_SEPARATOR . basename(__FILE__, '.phar') . '-' . md5_file(__FILE__){{end}};
+if (!is_dir($dir)) {
+    Phar::mapPhar();
+    (new Phar(__FILE__))->extractTo($dir, null, true);
+}
+{{- if .Entry}}
+require $dir . DIRECTORY_SEPARATOR . {{php .Entry}};
+{{- end}}
+__HALT_COMPILER(); ?>
+{{end}}`
+)
+
+// CLI return stub mapping archive alias and requiring entry, to run archive as command
+func CLI(options CLIOptions) ([]byte, error) {
+	if options.Entry == "" {
+		return nil, ErrMissingEntry
+	}
+	options.Alias = cmp.Or(options.Alias, "app.phar")
+	return render("cli", options)
+}
+
+// Web return stub serving archive files with Phar::webPhar, running index from CLI
+func Web(options WebOptions) ([]byte, error) {
+	options.Alias = cmp.Or(options.Alias, "app.phar")
+	options.Index = cmp.Or(options.Index, "index.php")
+	return render("web", options)
+}
+
+// SelfExtract return stub extracting archive files to folder once and requiring entry from there,
+// to code that need real files, like native extensions or exec calls
+func SelfExtract(options SelfExtractOptions) ([]byte, error) {
+	return render("selfextract", options)
+}
+
+// render execute named template, stubs end with "\r\n" after "?>" like PHP default stub
+func render(name string, data any) ([]byte, error) {
+	var stub bytes.Buffer
+	if err := templates.ExecuteTemplate(&stub, name, data); err != nil {
+		return nil, fmt.Errorf("cannot render %s stub: %w", name, err)
+	}
+	return append(bytes.TrimSuffix(stub.Bytes(), []byte("\n")), "\r\n"...), nil
+}
+
+// phpString quote value as PHP single quoted string
+func phpString(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
@@ -0,0 +1,180 @@
+package stubs
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Sirherobrine23/phargo"
+)
+
+// emptyArchive append manifest without entries to stub
+func emptyArchive(stub []byte) []byte {
+	le := binary.LittleEndian
+	manifest := le.AppendUint32(nil, 0)     // Entries count
+	manifest = append(manifest, 0x11, 0x00) // API version 1.1.0
+	manifest = le.AppendUint32(manifest, 0) // Global flags
+	manifest = le.AppendUint32(manifest, 0) // Alias length
+	manifest = le.AppendUint32(manifest, 0) // Metadata length
+	return append(le.AppendUint32(stub, uint32(len(manifest))), manifest...)
+}
+
+func TestStubs(t *testing.T) {
+	for name, test := range map[string]struct {
+		render func() ([]byte, error)
+		info   phargo.StubInfo
+	}{
+		"cli": {
+			render: func() ([]byte, error) {
+				return CLI(CLIOptions{Alias: "cli.phar", Entry: "bin/app.php", MinPHPVersion: "7.4.0"})
+			},
+			info: phargo.StubInfo{Kind: phargo.StubCustom, Alias: "cli.phar", PHPVersion: "7.4.0"},
+		},
+		"web": {
+			render: func() ([]byte, error) { return Web(WebOptions{Alias: "web.phar", NotFound: "404.php"}) },
+			info:   phargo.StubInfo{Kind: phargo.StubWebPhar},
+		},
+		"web_mime": {
+			render: func() ([]byte, error) {
+				types := MIMETypes([]string{"index.php", "style.css", "source.phps"})
+				return Web(WebOptions{Alias: "web_mime.phar", MIMETypes: types})
+			},
+			info: phargo.StubInfo{Kind: phargo.StubWebPhar},
+		},
+		"selfextract": {
+			render: func() ([]byte, error) { return SelfExtract(SelfExtractOptions{Entry: "run.php"}) },
+			info:   phargo.StubInfo{Kind: phargo.StubCustom},
+		},
+	} {
+		stub, err := test.render()
+		if err != nil {
+			t.Errorf("%s: Got error %s", name, err)
+			continue
+		}
+
+		// Golden files are run with real PHP by TestStubsPHP
+		if golden, err := os.ReadFile("testdata/" + name + ".stub"); err != nil {
+			t.Errorf("%s: Got error %s", name, err)
+			continue
+		} else if !bytes.Equal(stub, golden) {
+			t.Errorf("%s: stub differ from golden file:\n%s", name, stub)
+			continue
+		}
+
+		data := emptyArchive(stub)
+		pharInfo, err := phargo.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Errorf("%s: Got error %s", name, err)
+			continue
+		} else if pharInfo.Stub != test.info {
+			t.Errorf("%s: wrong stub info %+v", name, pharInfo.Stub)
+		}
+	}
+}
+
+// php run php binary in dir with TMPDIR set to dir, returning trimmed output
+func php(dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "php", args...)
+	cmd.Dir, cmd.Env = dir, append(os.Environ(), "TMPDIR="+dir)
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// serveGet start php built-in server with phar as router and GET each path, returning responses by path
+func serveGet(dir, phar string, paths ...string) (map[string]*http.Response, map[string]string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, err
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	server := exec.Command("php", "-S", addr, phar)
+	server.Dir = dir
+	if err := server.Start(); err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		server.Process.Kill()
+		server.Wait()
+	}()
+
+	responses, bodies := map[string]*http.Response{}, map[string]string{}
+	for _, path := range paths {
+		var res *http.Response
+		for retry := 0; ; retry++ {
+			if res, err = http.Get("http://" + addr + path); err == nil {
+				break
+			} else if retry == 50 {
+				return nil, nil, err
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		body, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		responses[path], bodies[path] = res, strings.TrimSpace(string(body))
+	}
+	return responses, bodies, nil
+}
+
+func TestStubsPHP(t *testing.T) {
+	if _, err := exec.LookPath("php"); err != nil {
+		t.Skip(err)
+		return
+	}
+
+	build, err := filepath.Abs("testdata/build.php")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	dir := t.TempDir()
+	if output, err := php(dir, "-d", "phar.readonly=0", build); err != nil {
+		t.Errorf("Cannot build phars: %s: %s", err, output)
+		return
+	}
+
+	for name, expected := range map[string]string{"cli.phar": "cli ok", "selfextract.phar": "selfextract ok"} {
+		if output, err := php(dir, name); err != nil || output != expected {
+			t.Errorf("%s: expected %q, got %q, %v", name, expected, output, err)
+		}
+	}
+
+	if _, bodies, err := serveGet(dir, "web.phar", "/", "/missing"); err != nil {
+		t.Error("web.phar:", err)
+	} else if bodies["/"] != "web ok" || bodies["/missing"] != "web 404" {
+		t.Errorf("web.phar: wrong responses %q", bodies)
+	}
+
+	if responses, bodies, err := serveGet(dir, "web_mime.phar", "/", "/style.css"); err != nil {
+		t.Error("web_mime.phar:", err)
+	} else if bodies["/"] != "web ok" || !strings.HasPrefix(responses["/style.css"].Header.Get("Content-Type"), "text/css") {
+		t.Errorf("web_mime.phar: wrong responses %q, style.css type %q", bodies, responses["/style.css"].Header.Get("Content-Type"))
+	}
+}
+
+func TestPHPString(t *testing.T) {
+	if quoted := phpString(`it's C:\dir\`); quoted != `'it\'s C:\\dir\\'` {
+		t.Errorf("Wrong quoted string: %s", quoted)
+		return
+	}
+	if _, err := CLI(CLIOptions{}); !errors.Is(err, ErrMissingEntry) {
+		t.Errorf("Should get ErrMissingEntry, got %v", err)
+		return
+	}
+}
@@ -0,0 +1,21 @@
+<?php
+// Build phars with golden stubs in current folder, run by TestStubsPHP with php -d phar.readonly=0 build.php, then:
+//   php cli.phar              print "cli ok"
+//   php -S 127.0.0.1:8080 web.phar, GET / print "web ok", GET /missing print "web 404"
+//   php -S 127.0.0.1:8080 web_mime.phar, GET /style.css has text/css type, GET /source.phps show highlighted source
+//   php selfextract.phar      print "selfextract ok" from extracted folder
+$builds = [
+    'cli' => ['bin/app.php' => '<?php echo "cli ok\n";'],
+    'web' => ['index.php' => '<?php echo "web ok\n";', '404.php' => '<?php echo "web 404\n";'],
+    'web_mime' => ['index.php' => '<?php echo "web ok\n";', 'style.css' => 'body {}', 'source.phps' => '<?php echo 1;'],
+    'selfextract' => ['run.php' => '<?php echo is_file(__FILE__) ? "selfextract ok\n" : "not extracted\n";'],
+];
+
+foreach ($builds as $name => $files) {
+    @unlink("$name.phar");
+    $p = new Phar("$name.phar");
+    foreach ($files as $file => $content) {
+        $p->addFromString($file, $content);
+    }
+    $p->setStub(file_get_contents(__DIR__ . "/$name.stub"));
+}
@@ -0,0 +1,91 @@
+package phargo
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// String return one line overview, like "phar 1.1.0, 4 entries, 36 bytes, sha256"
+func (phar *Phar) String() string {
+	var size int64
+	for _, file := range phar.Files {
+		size += file.SizeUncompressed
+	}
+
+	parts := []string{"phar"}
+	if phar.Manifest != nil {
+		parts[0] += " " + phar.Manifest.Version
+		if len(phar.Manifest.Alias) > 0 {
+			parts = append(parts, fmt.Sprintf("alias %q", phar.Manifest.Alias))
+		}
+	}
+	parts = append(parts, fmt.Sprintf("%d entries", len(phar.Files)), fmt.Sprintf("%d bytes", size), phar.signatureStatus())
+	return strings.Join(parts, ", ")
+}
+
+// Summary return one screen overview to logs and CLI: version, alias, stub, entries
+// count, total sizes, compression breakdown and signature status
+func (phar *Phar) Summary() string {
+	type compressionStats struct{ entries, compressed, uncompressed int64 }
+	var total compressionStats
+	var dirs int
+	stats := map[EntryFlags]*compressionStats{}
+	for _, file := range phar.Files {
+		if file.FileInfo().IsDir() {
+			dirs++
+			continue
+		}
+		kind := file.Flags.Compression()
+		if stats[kind] == nil {
+			stats[kind] = &compressionStats{}
+		}
+		for _, stat := range []*compressionStats{stats[kind], &total} {
+			stat.entries++
+			stat.compressed += file.SizeCompressed
+			stat.uncompressed += file.SizeUncompressed
+		}
+	}
+
+	var summary strings.Builder
+	if phar.Manifest != nil {
+		fmt.Fprintf(&summary, "api version: %s\n", phar.Manifest.Version)
+		if len(phar.Manifest.Alias) > 0 {
+			fmt.Fprintf(&summary, "alias: %s\n", phar.Manifest.Alias)
+		}
+		if len(phar.Manifest.Metadata) > 0 {
+			fmt.Fprintf(&summary, "metadata: %d bytes\n", len(phar.Manifest.Metadata))
+		}
+	}
+	fmt.Fprintf(&summary, "stub: %s\n", phar.Stub.Kind)
+	if phar.Stub.PHPVersion != "" {
+		fmt.Fprintf(&summary, "stub php version: %s\n", phar.Stub.PHPVersion)
+	}
+	fmt.Fprintf(&summary, "entries: %d files, %d folders\n", total.entries, dirs)
+	fmt.Fprintf(&summary, "size: %d bytes, %d bytes stored\n", total.uncompressed, total.compressed)
+	for _, kind := range slices.Sorted(maps.Keys(stats)) {
+		name, ok := compressionName[kind]
+		if !ok {
+			name = fmt.Sprintf("unknown %#x", kind)
+		}
+		stat := stats[kind]
+		fmt.Fprintf(&summary, "  %s: %d files, %d bytes, %d bytes stored\n", name, stat.entries, stat.uncompressed, stat.compressed)
+	}
+	fmt.Fprintf(&summary, "signature: %s\n", phar.signatureStatus())
+	if len(phar.Issues) > 0 {
+		fmt.Fprintf(&summary, "issues: %d\n", len(phar.Issues))
+	}
+	return summary.String()
+}
+
+// signatureStatus return signature algorithm and if it was verified
+func (phar *Phar) signatureStatus() string {
+	switch {
+	case phar.Signature == nil:
+		return "unsigned"
+	case phar.verified:
+		return phar.Signature.Signature.String() + " (verified)"
+	}
+	return phar.Signature.Signature.String() + " (not verified)"
+}
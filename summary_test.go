@@ -0,0 +1,34 @@
+package phargo
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	osFile, err := os.Open("./testdata/metadata_dir_sha256.phar")
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	defer osFile.Close()
+
+	file, err := NewReaderFromFile(osFile)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	if str := file.String(); !strings.HasPrefix(str, "phar 1.1.0, ") || !strings.HasSuffix(str, "sha256 (verified)") {
+		t.Errorf("Wrong string: %q", str)
+		return
+	}
+	summary := file.Summary()
+	for _, line := range []string{"api version: 1.1.0\n", "stub: default\n", "  none: ", "signature: sha256 (verified)\n"} {
+		if !strings.Contains(summary, line) {
+			t.Errorf("Summary should contain %q, got:\n%s", line, summary)
+			return
+		}
+	}
+}
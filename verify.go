@@ -0,0 +1,190 @@
+package phargo
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"runtime"
+	"slices"
+)
+
+// VerifyScope select checks run by [Phar.Verify]
+type VerifyScope int
+
+const (
+	VerifyScopeSignature VerifyScope = 1 << iota // Hash archive and compare with signature
+	VerifyScopeCRC                               // Decompress entries and compare with manifest CRC
+
+	VerifyScopeAll = VerifyScopeSignature | VerifyScopeCRC
+)
+
+// Verify run scope checks on archive parsed with [ReaderOptions.Trusted] or [ReaderOptions.SkipCRC],
+// so jobs needing only signature don't decompress all entries, and CRC only checks don't hash archive.
+//
+// Unsigned archives pass signature check, OpenSSL signatures return [ErrOpenssl].
+// First failed entry is returned as [*CRCError].
+func (phar *Phar) Verify(scope VerifyScope) error {
+	if scope&VerifyScopeSignature != 0 && phar.Signature != nil {
+		if phar.r == nil {
+			return fmt.Errorf("%w: archive reader not available", ErrInvalidSignature)
+		}
+		signature, err := getSignature(phar.r, phar.size, true, nil)
+		if err != nil {
+			return err
+		} else if signature.Signature != phar.Signature.Signature || !bytes.Equal(signature.Hash, phar.Signature.Hash) {
+			return fmt.Errorf("%w: signature changed since archive was parsed", ErrInvalidSignature)
+		}
+		phar.verified = true
+	}
+
+	if scope&VerifyScopeCRC != 0 {
+		for _, err := range verifyFiles(phar.Files, runtime.GOMAXPROCS(0), nil) {
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// VerifyingReader stream entries content like [archive/tar.Reader], checking each entry CRC
+// when its end is read and hashing archive bytes read to check signature on [VerifyingReader.Close],
+// so consumers extracting or proxying archive read it once instead of verify then read.
+//
+// Entries are visited in content order, [Phar.Files] order to archives from [NewReader].
+// It is not safe for concurrent use.
+type VerifyingReader struct {
+	phar    *Phar
+	files   []*File
+	digest  hash.Hash // Signature hash, nil to unsigned archives
+	offset  int64     // Archive offset hashed until
+	current *File
+	section *io.SectionReader // Current entry stored data
+	reader  io.ReadCloser     // Current entry content
+	crc     hash.Hash32
+	err     error // First integrity error
+}
+
+// NewVerifyingReader return [VerifyingReader] of archive, parsed with [ReaderOptions.Trusted]
+// to not read archive twice
+func NewVerifyingReader(phar *Phar) (*VerifyingReader, error) {
+	if phar.r == nil {
+		return nil, fmt.Errorf("%w: archive reader not available", ErrInvalidSignature)
+	}
+	verifier := &VerifyingReader{phar: phar, files: slices.Clone(phar.Files)}
+	slices.SortStableFunc(verifier.files, func(a, b *File) int { return cmp.Compare(a.dataOffset, b.dataOffset) })
+	if phar.Signature != nil {
+		if newHash, ok := signatureHash[phar.Signature.Signature]; ok {
+			verifier.digest = newHash()
+		}
+	}
+	return verifier, nil
+}
+
+// Next finish current entry and advance to next one, returning [io.EOF] after last entry
+func (verifier *VerifyingReader) Next() (*File, error) {
+	if err := verifier.finish(); err != nil {
+		return nil, err
+	} else if len(verifier.files) == 0 {
+		return nil, io.EOF
+	}
+
+	file := verifier.files[0]
+	verifier.files = verifier.files[1:]
+	if err := verifier.hashTo(file.dataOffset); err != nil {
+		return nil, err
+	}
+
+	verifier.section = io.NewSectionReader(verifier.phar.r, file.dataOffset, file.dataLen)
+	var raw io.Reader = verifier.section
+	if verifier.digest != nil {
+		raw = io.TeeReader(verifier.section, verifier.digest)
+	}
+	verifier.current, verifier.crc = file, crc32.NewIEEE()
+	verifier.reader = file.decompress(bufio.NewReaderSize(raw, int(min(int64(ReadBufferSize), max(file.dataLen, 16)))))
+	return file, nil
+}
+
+// Read current entry content, returning [*CRCError] instead of [io.EOF] if content not match CRC
+func (verifier *VerifyingReader) Read(p []byte) (int, error) {
+	if verifier.reader == nil {
+		return 0, io.EOF
+	}
+	n, err := verifier.reader.Read(p)
+	verifier.crc.Write(p[:n])
+	if err == io.EOF && !verifier.current.FileInfo().IsDir() && verifier.crc.Sum32() != verifier.current.CRC {
+		err = &CRCError{Filename: verifier.current.Filename, Expected: verifier.current.CRC, Got: verifier.crc.Sum32()}
+		verifier.err = cmp.Or(verifier.err, err)
+	}
+	return n, err
+}
+
+// Close read entries not read yet and archive until signature, returning first
+// integrity error found, [ErrInvalidSignature] if archive hash not match signature
+// and [ErrOpenssl] to OpenSSL signatures after entries CRC are checked
+func (verifier *VerifyingReader) Close() error {
+	for {
+		if _, err := verifier.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	if verifier.err != nil {
+		return verifier.err
+	} else if verifier.phar.Signature == nil {
+		return nil
+	} else if verifier.digest == nil {
+		return ErrOpenssl
+	}
+
+	signature := verifier.phar.Signature
+	signatureStart := verifier.phar.size - int64(len(signature.Hash)) - int64(pharSignatureStubLen)
+	if err := verifier.hashTo(signatureStart); err != nil {
+		return err
+	} else if !bytes.Equal(verifier.digest.Sum(nil), signature.Hash) {
+		return fmt.Errorf("%w: %s hash mismatch", ErrInvalidSignature, signature.Signature)
+	}
+	verifier.phar.verified = true
+	return nil
+}
+
+// finish read rest of current entry, so its CRC is checked and stored data hashed
+func (verifier *VerifyingReader) finish() error {
+	if verifier.current == nil {
+		return nil
+	}
+	_, err := copyBuffer(io.Discard, verifier)
+	var crcErr *CRCError
+	if err != nil && !errors.As(err, &crcErr) {
+		return err
+	}
+	verifier.reader.Close()
+	if verifier.digest != nil {
+		if _, err := copyBuffer(verifier.digest, verifier.section); err != nil {
+			return err
+		}
+	}
+	verifier.offset = verifier.current.dataOffset + verifier.current.dataLen
+	verifier.current, verifier.reader = nil, nil
+	return nil
+}
+
+// hashTo hash archive bytes between last hashed offset and offset
+func (verifier *VerifyingReader) hashTo(offset int64) error {
+	if offset <= verifier.offset {
+		return nil
+	}
+	if verifier.digest != nil {
+		if _, err := io.CopyN(verifier.digest, newSectionReader(verifier.phar.r, verifier.offset, offset-verifier.offset), offset-verifier.offset); err != nil {
+			return err
+		}
+	}
+	verifier.offset = offset
+	return nil
+}
@@ -0,0 +1,121 @@
+package phargo
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	data := signPhar(buildPhar(
+		testEntry{name: "a.txt", data: []byte("AAAA")},
+		testEntry{name: "b.txt", data: []byte("BBBB"), crc: 1},
+	))
+	file, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{Trusted: true})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	if err := file.Verify(VerifyScopeSignature); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if !file.verified {
+		t.Error("Signature not marked verified")
+		return
+	}
+	if err := file.Verify(VerifyScopeCRC); !errors.Is(err, ErrBadCRC) {
+		t.Errorf("Should get ErrBadCRC, got %v", err)
+		return
+	}
+
+	// Content changed after parse
+	data[bytes.Index(data, []byte("AAAA"))] = 'X'
+	if err := file.Verify(VerifyScopeAll); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Should get ErrInvalidSignature, got %v", err)
+		return
+	}
+}
+
+func TestVerifyingReader(t *testing.T) {
+	for _, name := range []string{"gz.phar", "metadata_dir_sha256.phar", "simple.phar", "bad_hash.phar"} {
+		data, err := os.ReadFile("./testdata/" + name)
+		if err != nil {
+			t.Skip(err)
+			return
+		}
+		file, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{Trusted: true})
+		if err != nil {
+			t.Errorf("%s: Got error %s", name, err)
+			return
+		}
+		verifier, err := NewVerifyingReader(file)
+		if err != nil {
+			t.Errorf("%s: Got error %s", name, err)
+			return
+		}
+
+		for {
+			entry, err := verifier.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("%s: Got error %s", name, err)
+				return
+			}
+			content, err := io.ReadAll(verifier)
+			if err != nil {
+				t.Errorf("%s: Got error %s", name, err)
+				return
+			} else if expected, _ := fs.ReadFile(file, entry.Filename); !entry.FileInfo().IsDir() && !bytes.Equal(content, expected) {
+				t.Errorf("%s: wrong %s content %q", name, entry.Filename, content)
+				return
+			}
+		}
+
+		err = verifier.Close()
+		if name == "bad_hash.phar" {
+			if !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("%s: Should get ErrInvalidSignature, got %v", name, err)
+			}
+			continue
+		} else if err != nil || !file.verified {
+			t.Errorf("%s: Got error %v", name, err)
+			return
+		}
+	}
+}
+
+func TestVerifyingReaderCRC(t *testing.T) {
+	data := signPhar(buildPhar(
+		testEntry{name: "a.txt", data: []byte("AAAA"), crc: 1},
+		testEntry{name: "b.txt", data: []byte("BBBB")},
+	))
+	file, err := NewReaderWithOptions(bytes.NewReader(data), int64(len(data)), ReaderOptions{Trusted: true})
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+	verifier, err := NewVerifyingReader(file)
+	if err != nil {
+		t.Error("Got error", err)
+		return
+	}
+
+	if _, err := verifier.Next(); err != nil {
+		t.Error("Got error", err)
+		return
+	} else if _, err := io.ReadAll(verifier); !errors.Is(err, ErrBadCRC) {
+		t.Errorf("Should get ErrBadCRC at entry end, got %v", err)
+		return
+	}
+
+	// b.txt not read, Close read it and report first error
+	if err := verifier.Close(); !errors.Is(err, ErrBadCRC) {
+		t.Errorf("Should get ErrBadCRC on Close, got %v", err)
+		return
+	}
+}